@@ -71,6 +71,11 @@ var (
 
 	fullKeys []key.Binding = []key.Binding{
 		keyset.Status,
+		keyset.Duplicates,
+		keyset.Import,
+		keyset.History,
+		keyset.AuditLog,
+		keyset.ExportPatch,
 	}
 
 	delegate teax.Delegate[Item] = teax.Delegate[Item]{
@@ -85,7 +90,7 @@ var (
 func New(squad *squad.SQuAD, title string, dataset teax.Dataset) App {
 	return App{
 		Model: teax.Model[Item]{
-			List:    teax.NewList(squad.Articles, title, delegate),
+			List:    teax.Guard(teax.NewList(squad.Articles, title, delegate), dataset),
 			Coll:    squad,
 			Dataset: dataset,
 			Form:    form,
@@ -106,6 +111,11 @@ func (m App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.Mode = nil
 			return m, nil
 		}
+
+		screens, ok := m.Dataset.(teax.Screens)
+		if ok && key.Matches(msg, keyset.Import) && !m.Dataset.ReadOnly() && m.Mode == nil && m.List.Unfiltered() {
+			return m, bubblon.Open(screens.Import())
+		}
 	}
 
 	m.Model, cmd = m.Model.Update(msg)
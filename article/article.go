@@ -85,6 +85,9 @@ var (
 		keyset.Next,
 		keyset.Prev,
 		keyset.Status,
+		keyset.Duplicates,
+		keyset.History,
+		keyset.AuditLog,
 	}
 
 	delegate teax.Delegate[Item] = teax.Delegate[Item]{
@@ -103,7 +106,7 @@ func New(
 ) Article {
 	return Article{
 		Model: teax.Model[Item]{
-			List:    teax.NewList(article.Paragraphs, article.Title(), delegate),
+			List:    teax.Guard(teax.NewList(article.Paragraphs, article.Title(), delegate), dataset),
 			Coll:    article,
 			Dataset: dataset,
 			Form:    form,
@@ -154,7 +157,7 @@ func (m Article) View() string {
 	}
 
 	sections := make([]string, 0, numSections)
-	if len(m.Coll.All()) == 0 && (m.Mode == nil || m.InSync) {
+	if len(m.Coll.All()) == 0 && m.Mode == nil {
 		m.List.IncreaseHeight(m.viewport.Height())
 	}
 	sections = append(sections, m.ListView())
@@ -163,7 +166,7 @@ func (m Article) View() string {
 	case Context:
 		sections = append(sections, m.Mode.View())
 	default:
-		if m.Mode != nil || m.InSync {
+		if m.Mode != nil {
 			m.viewport.Blur()
 		}
 		if len(m.Coll.All()) > 0 {
@@ -181,7 +184,7 @@ func (m Article) View() string {
 }
 
 func (m *Article) updateContext() {
-	if m.List.ItemSelected() && !m.InSync {
+	if m.List.ItemSelected() {
 		p := m.Coll.Get(m.List.GlobalIndex())
 		if len(p.QAs) == 1 {
 			qa := p.QAs[0]
@@ -0,0 +1,306 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+
+	"github.com/donderom/sqwat/diff"
+	"github.com/donderom/sqwat/journal"
+	"github.com/donderom/sqwat/squad"
+	"github.com/donderom/sqwat/text"
+	"github.com/donderom/sqwat/validation"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// Entry is an edit applied by User. Before is empty for created items
+// and After for deleted ones.
+type Entry struct {
+	Time time.Time           `json:"time"`
+	User string              `json:"user"`
+	Kind journal.Kind        `json:"op"`
+	Type validation.ItemType `json:"type"`
+	Path validation.Path     `json:"path"`
+	// ID of the question edited or the one of the edited answer
+	ID     string         `json:"id,omitzero"`
+	Before jsontext.Value `json:"before,omitzero"`
+	After  jsontext.Value `json:"after,omitzero"`
+}
+
+var _ list.DefaultItem = Entry{}
+
+func (e Entry) Title() string {
+	return fmt.Sprintf("%s %s %s", e.Type, e.Kind, e.Location())
+}
+
+func (e Entry) Description() string {
+	description := e.Time.Local().Format(time.DateTime) + " by " + e.User
+	if e.ID != "" {
+		description += " · id " + e.ID
+	}
+	return description
+}
+
+func (e Entry) FilterValue() string {
+	return strings.Join([]string{e.Title(), e.User, e.ID}, " ")
+}
+
+// Location is where the item was at the time of the edit.
+func (e Entry) Location() string {
+	location := make([]string, 0, len(e.Path))
+	for itemType := validation.Article; itemType <= e.Type; itemType++ {
+		index, ok := e.Path[itemType]
+		if !ok {
+			break
+		}
+		location = append(location, fmt.Sprintf("%s %d", strings.ToLower(itemType.String()), index+1))
+	}
+	return "in " + strings.Join(location, ", ")
+}
+
+// Render shows the edit in the word diff notation.
+func (e Entry) Render() string {
+	return diff.Inline(text.Diff(indent(e.Before), indent(e.After)))
+}
+
+func indent(value jsontext.Value) string {
+	if len(value) == 0 {
+		return ""
+	}
+	value = value.Clone()
+	if err := value.Indent(); err != nil {
+		return string(value)
+	}
+	return string(value)
+}
+
+// Path is the audit log of the edits of filename.
+func Path(filename string) string {
+	return filename + ".audit"
+}
+
+// User is the name recorded when none is set up.
+func User() string {
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return "unknown"
+}
+
+// New audits op journaled for data by username, before is the item
+// in place of the edited one.
+func New(data *squad.SQuAD, op journal.Op, before any, username string) (Entry, error) {
+	if len(op.Path) == 0 || len(op.Path) > int(validation.Answer)+1 {
+		return Entry{}, fmt.Errorf("path %v is not of an item", op.Path)
+	}
+
+	entry := Entry{
+		Time: time.Now(),
+		User: username,
+		Kind: op.Kind,
+		Type: validation.ItemType(len(op.Path) - 1),
+		Path: make(validation.Path, len(op.Path)),
+		ID:   id(data, op, before),
+	}
+	for i, index := range op.Path {
+		entry.Path[validation.ItemType(i)] = index
+	}
+
+	if op.Kind != journal.Deleted {
+		entry.After = op.Value
+	}
+	if op.Kind != journal.Created {
+		value, err := json.Marshal(before)
+		if err != nil {
+			return Entry{}, err
+		}
+		entry.Before = value
+	}
+
+	return entry, nil
+}
+
+// Changes audits the edits made from old to new by username at once,
+// as a batch of ops or an import does without journaling them.
+func Changes(old, new *squad.SQuAD, username string) ([]Entry, error) {
+	now := time.Now()
+	changes := diff.Compare(old, new)
+	entries := make([]Entry, 0, len(changes))
+
+	for _, c := range changes {
+		entry := Entry{Time: now, User: username, Type: c.Type, Path: c.Path()}
+
+		var err error
+		switch c.Kind {
+		case diff.Added:
+			entry.Kind = journal.Created
+			entry.After, err = json.Marshal(item(new, c.NewPath, c.Type))
+		case diff.Removed:
+			entry.Kind = journal.Deleted
+			entry.Before, err = json.Marshal(item(old, c.OldPath, c.Type))
+		default:
+			entry.Kind = journal.Updated
+			entry.Before, err = json.Marshal(item(old, c.OldPath, c.Type))
+			if err == nil {
+				entry.After, err = json.Marshal(item(new, c.NewPath, c.Type))
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if c.Type == validation.Question {
+			if c.Kind == diff.Removed {
+				entry.ID = item(old, c.OldPath, c.Type).(squad.QA).Id
+			} else {
+				entry.ID = item(new, c.NewPath, c.Type).(squad.QA).Id
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// item is the article, paragraph or question at path of data.
+func item(data *squad.SQuAD, path validation.Path, itemType validation.ItemType) any {
+	article := data.At(path.To(validation.Article))
+	if itemType == validation.Article {
+		return *article
+	}
+
+	paragraph := article.At(path.To(validation.Paragraph))
+	if itemType == validation.Paragraph {
+		return *paragraph
+	}
+
+	return *paragraph.At(path.To(validation.Question))
+}
+
+func id(data *squad.SQuAD, op journal.Op, before any) string {
+	if len(op.Path) < int(validation.Question)+1 {
+		return ""
+	}
+	if qa, ok := before.(squad.QA); ok && op.Kind == journal.Deleted {
+		return qa.Id
+	}
+	return data.At(op.Path[0]).At(op.Path[1]).At(op.Path[2]).Id
+}
+
+// Append writes entries to the audit log of filename.
+func Append(filename string, entries ...Entry) error {
+	file, err := os.OpenFile(Path(filename), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(file)
+	for _, entry := range entries {
+		var line []byte
+		if line, err = json.Marshal(entry); err != nil {
+			break
+		}
+		if _, err = w.Write(append(line, '\n')); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// Read loads the audit log of filename, there is none before the
+// first edit.
+func Read(filename string) ([]Entry, error) {
+	file, err := os.Open(Path(filename))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var entry Entry
+		if err = json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", Path(filename), line, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
+
+// History keeps the entries of the item at index of coll, one of the
+// collections of data, and of the items within it. Questions and
+// answers are told by the question ID when there is one as their
+// indices shift with the edits around them.
+func History(entries []Entry, data *squad.SQuAD, coll any, index int) []Entry {
+	parents, ok := journal.Locate(data, coll)
+	if !ok {
+		return nil
+	}
+
+	itemType := validation.ItemType(len(parents))
+	path := validation.Path{itemType: index}
+	for i, parent := range parents {
+		path[validation.ItemType(i)] = parent
+	}
+
+	var id string
+	switch c := coll.(type) {
+	case *squad.Paragraph:
+		id = c.Get(index).Id
+	case *squad.QA:
+		id = c.Id
+	}
+
+	var result []Entry
+	for _, e := range entries {
+		if e.Type < itemType {
+			continue
+		}
+
+		from := validation.Article
+		if id != "" {
+			if e.ID != id {
+				continue
+			}
+			from = validation.Answer
+		}
+
+		within := true
+		for t := from; t <= itemType; t++ {
+			if e.Path[t] != path[t] {
+				within = false
+				break
+			}
+		}
+		if within {
+			result = append(result, e)
+		}
+	}
+	return result
+}
@@ -0,0 +1,151 @@
+package audit_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/donderom/sqwat/audit"
+	"github.com/donderom/sqwat/journal"
+	"github.com/donderom/sqwat/squad"
+	"github.com/donderom/sqwat/validation"
+)
+
+func dataset() *squad.SQuAD {
+	return &squad.SQuAD{
+		Version: "v2.0",
+		Articles: []squad.Article{{
+			Name: "Go",
+			Paragraphs: []squad.Paragraph{{
+				Context: "Go was designed at Google.",
+				QAs: []squad.QA{
+					{
+						Id:             "q1",
+						Question:       "Who designed Go?",
+						CorrectAnswers: []squad.Answer{{Text: "Google", Start: 19}},
+					},
+					{Id: "q2", Question: "When was Go designed?"},
+				},
+			}},
+		}},
+	}
+}
+
+// edit audits the edit of the item at index of coll.
+func edit(t *testing.T, data *squad.SQuAD, kind journal.Kind, coll any, index int, before any) audit.Entry {
+	t.Helper()
+
+	op, err := journal.NewOp(data, kind, coll, index)
+	require.NoError(t, err)
+	entry, err := audit.New(data, op, before, "alice")
+	require.NoError(t, err)
+	return entry
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	data := dataset()
+	paragraph := data.At(0).At(0)
+	before := paragraph.Get(0)
+	paragraph.QAs[0].Question = "Who made Go?"
+
+	entry := edit(t, data, journal.Updated, paragraph, 0, before)
+	assert.Equal(t, validation.Question, entry.Type)
+	assert.Equal(t, validation.Path{validation.Article: 0, validation.Paragraph: 0, validation.Question: 0}, entry.Path)
+	assert.Equal(t, "q1", entry.ID)
+	assert.Equal(t, "alice", entry.User)
+	assert.Contains(t, string(entry.Before), "Who designed Go?")
+	assert.Contains(t, string(entry.After), "Who made Go?")
+	assert.Equal(t, "Question updated in article 1, paragraph 1, question 1", entry.Title())
+
+	deleted := paragraph.Get(1)
+	paragraph.Remove(1)
+	entry = edit(t, data, journal.Deleted, paragraph, 1, deleted)
+	assert.Equal(t, "q2", entry.ID)
+	assert.Empty(t, entry.After)
+	assert.Contains(t, string(entry.Before), "When was Go designed?")
+}
+
+func TestChanges(t *testing.T) {
+	t.Parallel()
+
+	old, data := dataset(), dataset()
+	paragraph := data.At(0).At(0)
+	paragraph.QAs[0].Question = "Who made Go?"
+	paragraph.Remove(1)
+	paragraph.QAs = append(paragraph.QAs, squad.QA{Id: "q3", Question: "Is Go compiled?"})
+
+	entries, err := audit.Changes(old, data, "alice")
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	kinds := make(map[journal.Kind]audit.Entry, len(entries))
+	for _, entry := range entries {
+		assert.Equal(t, validation.Question, entry.Type)
+		assert.Equal(t, "alice", entry.User)
+		kinds[entry.Kind] = entry
+	}
+
+	updated := kinds[journal.Updated]
+	assert.Equal(t, "q1", updated.ID)
+	assert.Contains(t, string(updated.Before), "Who designed Go?")
+	assert.Contains(t, string(updated.After), "Who made Go?")
+
+	deleted := kinds[journal.Deleted]
+	assert.Equal(t, "q2", deleted.ID)
+	assert.Equal(t, 1, deleted.Path.To(validation.Question))
+	assert.Empty(t, deleted.After)
+
+	created := kinds[journal.Created]
+	assert.Equal(t, "q3", created.ID)
+	assert.Empty(t, created.Before)
+	assert.Contains(t, string(created.After), "Is Go compiled?")
+}
+
+func TestAppendRead(t *testing.T) {
+	t.Parallel()
+
+	filename := filepath.Join(t.TempDir(), "data.json")
+	entries, err := audit.Read(filename)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	data := dataset()
+	article := data.At(0)
+	entry := edit(t, data, journal.Updated, article, 0, article.Get(0))
+	require.NoError(t, audit.Append(filename, entry))
+	require.NoError(t, audit.Append(filename, entry))
+
+	entries, err = audit.Read(filename)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, entry.Path, entries[1].Path)
+	assert.Equal(t, entry.Type, entries[1].Type)
+	assert.True(t, entry.Time.Equal(entries[1].Time))
+}
+
+func TestHistory(t *testing.T) {
+	t.Parallel()
+
+	data := dataset()
+	article := data.At(0)
+	paragraph := article.At(0)
+	qa := paragraph.At(0)
+
+	entries := []audit.Entry{
+		edit(t, data, journal.Updated, data, 0, data.Get(0)),
+		edit(t, data, journal.Updated, article, 0, article.Get(0)),
+		edit(t, data, journal.Updated, paragraph, 0, paragraph.Get(0)),
+		edit(t, data, journal.Updated, paragraph, 1, paragraph.Get(1)),
+		edit(t, data, journal.Updated, qa, 0, qa.Get(0)),
+	}
+
+	assert.Equal(t, entries, audit.History(entries, data, data, 0))
+	assert.Equal(t, entries[1:], audit.History(entries, data, article, 0))
+	assert.Equal(t, []audit.Entry{entries[2], entries[4]}, audit.History(entries, data, paragraph, 0))
+	assert.Equal(t, []audit.Entry{entries[3]}, audit.History(entries, data, paragraph, 1))
+	assert.Equal(t, []audit.Entry{entries[4]}, audit.History(entries, data, qa, 0))
+}
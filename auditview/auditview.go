@@ -0,0 +1,130 @@
+package auditview
+
+import (
+	"slices"
+
+	"github.com/donderom/sqwat/audit"
+	"github.com/donderom/sqwat/keyset"
+	"github.com/donderom/sqwat/style"
+	"github.com/donderom/sqwat/teax"
+	"github.com/donderom/sqwat/text"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/donderom/bubblon"
+)
+
+type Item = audit.Entry
+
+type Audit struct {
+	list    teax.ViewList[Item, text.Segment]
+	entries []Item
+	err     error
+}
+
+var _ tea.Model = Audit{}
+
+var (
+	keys []key.Binding = []key.Binding{
+		keyset.Esc,
+	}
+
+	fullKeys []key.Binding = []key.Binding{
+		keyset.Next,
+		keyset.Prev,
+	}
+
+	delegate teax.Delegate[Item] = teax.Delegate[Item]{
+		Style:           entryStyle,
+		ItemName:        "edit",
+		ShowDescription: true,
+		ShortHelpKeys:   keys,
+		FullHelpKeys:    slices.Concat(keys, fullKeys),
+	}
+
+	entryStyle teax.StyleFunc[Item] = teax.StyleFunc[Item](
+		func(defaultStyles teax.Styles) teax.ItemStyles[Item] {
+			return func(item Item) teax.Styles {
+				styles := defaultStyles
+				switch {
+				case len(item.Before) == 0:
+					border := style.Border.Multi
+					styles.NormalDesc = border.Apply(styles.NormalDesc)
+					styles.SelectedDesc = border.Apply(styles.SelectedDesc)
+				case len(item.After) == 0:
+					border := style.Border.Error
+					styles.NormalDesc = border.Apply(styles.NormalDesc)
+					styles.SelectedDesc = border.Apply(styles.SelectedDesc)
+				}
+				return styles
+			}
+		})
+)
+
+// New browses the entries of an audit log, the latest first. The list
+// filter narrows them down by item, user or question ID.
+func New(title string, entries []Item, err error) Audit {
+	entries = slices.Clone(entries)
+	slices.Reverse(entries)
+
+	return Audit{
+		list:    teax.NewViewList[text.Segment](entries, title, delegate),
+		entries: entries,
+		err:     err,
+	}
+}
+
+func (m Audit) Init() tea.Cmd {
+	if m.err != nil {
+		return m.list.NewStatus(style.Error.Render(m.err.Error()))
+	}
+	return nil
+}
+
+func (m Audit) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.Resize(msg)
+		m.updateContent()
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.list.Unfiltered() {
+			switch {
+			case key.Matches(msg, keyset.Esc):
+				return m, bubblon.Close
+
+			case key.Matches(msg, keyset.Quit):
+				return m, tea.Quit
+			}
+		}
+	}
+
+	m.list, cmd = m.list.Update(msg)
+	m.updateContent()
+	return m, cmd
+}
+
+func (m Audit) View() string {
+	helpView := m.list.Help.View(m.list)
+	m.list.DecreaseHeight(lipgloss.Height(helpView))
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		style.Top.Render(m.list.View()),
+		m.list.Viewport.View(),
+		style.Bot.Render(helpView),
+	)
+}
+
+func (m *Audit) updateContent() {
+	if m.list.ItemSelected() {
+		m.list.Viewport.SetContent(m.entries[m.list.GlobalIndex()].Render())
+	} else {
+		m.list.Viewport.Blur()
+		m.list.Viewport.SetContent("")
+	}
+}
@@ -0,0 +1,311 @@
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/donderom/sqwat/squad"
+	"github.com/donderom/sqwat/validation"
+)
+
+type Kind string
+
+const (
+	Add          Kind = "add"
+	Update       Kind = "update"
+	Delete       Kind = "delete"
+	Move         Kind = "move"
+	Set          Kind = "set"
+	RegenerateID Kind = "regenerate-id"
+)
+
+// Op is an edit of the item at Path, the indices of the article,
+// paragraph, question and answer as deep as the item is. With ID the
+// question is found by its ID and Path is the answer within it if any.
+type Op struct {
+	Kind Kind   `json:"op"`
+	Path []int  `json:"path,omitzero"`
+	ID   string `json:"id,omitzero"`
+	// Where a moved item ends up
+	To []int `json:"to,omitzero"`
+	// JSON name of the field to set
+	Field string         `json:"field,omitzero"`
+	Value jsontext.Value `json:"value,omitzero"`
+	// Line of the op in the batch file
+	Line int `json:"-"`
+}
+
+// Result is an op done, Path is where the item was found.
+type Result struct {
+	Op   Op
+	Path []int
+}
+
+func (r Result) String() string {
+	itemType := validation.ItemType(len(r.Path) - 1)
+	s := fmt.Sprintf("line %d: %s %s at %s", r.Op.Line, r.Op.Kind, strings.ToLower(itemType.String()), location(r.Path))
+	switch r.Op.Kind {
+	case Move:
+		s += " to " + location(r.Op.To)
+	case Set:
+		s += " " + r.Op.Field + " to " + string(r.Op.Value)
+	}
+	return s
+}
+
+func location(path []int) string {
+	location := make([]string, len(path))
+	for i, index := range path {
+		location[i] = fmt.Sprintf("%s %d", strings.ToLower(validation.ItemType(i).String()), index+1)
+	}
+	return strings.Join(location, ", ")
+}
+
+// Read loads the ops of a batch file, one per line. Blank lines are
+// skipped.
+func Read(r io.Reader) ([]Op, error) {
+	var ops []Op
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		op := Op{Line: line}
+		if err := json.Unmarshal(scanner.Bytes(), &op, json.RejectUnknownMembers(true)); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		ops = append(ops, op)
+	}
+	return ops, scanner.Err()
+}
+
+// Apply runs the ops in order as one transaction, data is only changed
+// when all of them succeed.
+func Apply(data *squad.SQuAD, ops []Op) ([]Result, error) {
+	result := data.Clone()
+	results := make([]Result, 0, len(ops))
+
+	for _, op := range ops {
+		path, err := resolve(result, op)
+		if err == nil {
+			err = apply(result, op, path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", op.Line, op.Kind, err)
+		}
+		results = append(results, Result{Op: op, Path: path})
+	}
+
+	*data = *result
+	return results, nil
+}
+
+// resolve finds the path of the item op is about.
+func resolve(data *squad.SQuAD, op Op) ([]int, error) {
+	if op.ID == "" {
+		if len(op.Path) == 0 || len(op.Path) > int(validation.Answer)+1 {
+			return nil, fmt.Errorf("path %v is not of an item", op.Path)
+		}
+		return op.Path, nil
+	}
+
+	if len(op.Path) > 1 {
+		return nil, fmt.Errorf("path %v is not of an answer", op.Path)
+	}
+
+	var found []int
+	for i, a := range data.Articles {
+		for j, p := range a.Paragraphs {
+			for k, qa := range p.QAs {
+				if qa.Id != op.ID {
+					continue
+				}
+				if found != nil {
+					return nil, fmt.Errorf("question ID %q is not unique", op.ID)
+				}
+				found = []int{i, j, k}
+			}
+		}
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("no question with ID %q", op.ID)
+	}
+	return append(found, op.Path...), nil
+}
+
+var errTooDeep = errors.New("path is too deep")
+
+// parent finds the collection of the item at path.
+func parent(data *squad.SQuAD, path []int) (any, error) {
+	var coll any = data
+	for _, index := range path[:len(path)-1] {
+		var err error
+		switch c := coll.(type) {
+		case *squad.SQuAD:
+			coll, err = child(c, index)
+		case *squad.Article:
+			coll, err = child(c, index)
+		case *squad.Paragraph:
+			coll, err = child(c, index)
+		default:
+			err = errTooDeep
+		}
+		if err != nil {
+			return nil, fmt.Errorf("path %v: %w", path, err)
+		}
+	}
+	return coll, nil
+}
+
+type collection[Item any] interface {
+	Insert(index int, item Item)
+	Update(index int, item Item)
+	Remove(index int)
+	Get(index int) Item
+	At(index int) *Item
+	All() []Item
+}
+
+func child[Item any](coll collection[Item], index int) (*Item, error) {
+	if err := check(index, len(coll.All())-1); err != nil {
+		return nil, err
+	}
+	return coll.At(index), nil
+}
+
+func check(index, last int) error {
+	if index < 0 || index > last {
+		return fmt.Errorf("index %d out of range", index)
+	}
+	return nil
+}
+
+func apply(data *squad.SQuAD, op Op, path []int) error {
+	coll, err := parent(data, path)
+	if err != nil {
+		return err
+	}
+
+	switch c := coll.(type) {
+	case *squad.SQuAD:
+		return run(data, c, op, path)
+	case *squad.Article:
+		return run(data, c, op, path)
+	case *squad.Paragraph:
+		return run(data, c, op, path)
+	case *squad.QA:
+		return run(data, c, op, path)
+	}
+	return errTooDeep
+}
+
+// run does op to the item at the end of path in coll.
+func run[Item any](data *squad.SQuAD, coll collection[Item], op Op, path []int) error {
+	index, last := path[len(path)-1], len(coll.All())-1
+	if op.Kind == Add {
+		last++
+	}
+	if err := check(index, last); err != nil {
+		return err
+	}
+
+	switch op.Kind {
+	case Add, Update:
+		item, err := decode[Item](op.Value)
+		if err != nil {
+			return err
+		}
+		if op.Kind == Add {
+			coll.Insert(index, item)
+		} else {
+			coll.Update(index, item)
+		}
+
+	case Delete:
+		coll.Remove(index)
+
+	case Set:
+		item, err := set(coll.Get(index), op.Field, op.Value)
+		if err != nil {
+			return err
+		}
+		coll.Update(index, item)
+
+	case RegenerateID:
+		qa, ok := any(coll.Get(index)).(squad.QA)
+		if !ok {
+			return errors.New("only questions have IDs")
+		}
+		qa.GenerateID()
+		coll.Update(index, any(qa).(Item))
+
+	case Move:
+		if len(op.To) != len(path) {
+			return fmt.Errorf("to %v is not where the item goes", op.To)
+		}
+
+		item := coll.Get(index)
+		coll.Remove(index)
+
+		// The destination is found once the item is out of the way
+		to, err := parent(data, op.To)
+		if err != nil {
+			return err
+		}
+		target, ok := to.(collection[Item])
+		if !ok {
+			return fmt.Errorf("to %v is not where the item goes", op.To)
+		}
+		index = op.To[len(op.To)-1]
+		if err = check(index, len(target.All())); err != nil {
+			return fmt.Errorf("to %v: %w", op.To, err)
+		}
+		target.Insert(index, item)
+
+	default:
+		return fmt.Errorf("unknown op %q", op.Kind)
+	}
+
+	return nil
+}
+
+func decode[Item any](value jsontext.Value) (Item, error) {
+	var item Item
+	if len(value) == 0 {
+		return item, errors.New("missing value")
+	}
+	err := json.Unmarshal(value, &item, json.RejectUnknownMembers(true))
+	return item, err
+}
+
+// set changes the field of item by its JSON name.
+func set[Item any](item Item, field string, value jsontext.Value) (Item, error) {
+	if field == "" {
+		return item, errors.New("missing field")
+	}
+
+	doc, err := json.Marshal(item)
+	if err != nil {
+		return item, err
+	}
+
+	var fields map[string]jsontext.Value
+	if err = json.Unmarshal(doc, &fields); err != nil {
+		return item, err
+	}
+	fields[field] = value
+
+	if doc, err = json.Marshal(fields); err != nil {
+		return item, err
+	}
+	return decode[Item](doc)
+}
@@ -0,0 +1,96 @@
+package batch_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/donderom/sqwat/batch"
+	"github.com/donderom/sqwat/squad"
+)
+
+func dataset() *squad.SQuAD {
+	return &squad.SQuAD{
+		Version: "v2.0",
+		Articles: []squad.Article{
+			{
+				Name: "Go",
+				Paragraphs: []squad.Paragraph{{
+					Context: "Go was designed at Google.",
+					QAs: []squad.QA{{
+						Id:             "q1",
+						Question:       "Who designed Go?",
+						CorrectAnswers: []squad.Answer{{Text: "Google", Start: 19}},
+					}},
+				}},
+			},
+			{Name: "Rust", Paragraphs: []squad.Paragraph{{Context: "Rust is a language.", QAs: []squad.QA{}}}},
+		},
+	}
+}
+
+func read(t *testing.T, ops string) []batch.Op {
+	t.Helper()
+
+	result, err := batch.Read(strings.NewReader(ops))
+	require.NoError(t, err)
+	return result
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	data := dataset()
+	ops := read(t, `{"op":"add","path":[2],"value":{"title":"Zig","paragraphs":[]}}
+{"op":"set","path":[1,0],"field":"context","value":"Rust is a systems language."}
+{"op":"update","id":"q1","path":[0],"value":{"text":"Google","answer_start":19}}
+{"op":"add","id":"q1","path":[1],"value":{"text":"at Google","answer_start":16}}
+
+{"op":"move","id":"q1","to":[1,0,0]}
+{"op":"set","path":[1,0,0],"field":"question","value":"Who made Go?"}
+{"op":"regenerate-id","path":[1,0,0]}
+{"op":"delete","path":[0]}`)
+
+	results, err := batch.Apply(data, ops)
+	require.NoError(t, err)
+	require.Len(t, results, len(ops))
+	assert.Equal(t, "line 6: move question at article 1, paragraph 1, question 1 to article 2, paragraph 1, question 1", results[4].String())
+	assert.Equal(t, 8, results[6].Op.Line)
+
+	require.Len(t, data.Articles, 2)
+	assert.Equal(t, "Zig", data.Articles[1].Name)
+	rust := data.Articles[0].Paragraphs[0]
+	assert.Equal(t, "Rust is a systems language.", rust.Context)
+	require.Len(t, rust.QAs, 1)
+	assert.Equal(t, "Who made Go?", rust.QAs[0].Question)
+	assert.NotEqual(t, "q1", rust.QAs[0].Id)
+	assert.Len(t, rust.QAs[0].Answers(), 2)
+}
+
+func TestApplyTransaction(t *testing.T) {
+	t.Parallel()
+
+	for name, ops := range map[string]string{
+		"out of range":  `{"op":"delete","path":[0]}` + "\n" + `{"op":"delete","path":[5]}`,
+		"unknown id":    `{"op":"delete","id":"q9"}`,
+		"unknown field": `{"op":"set","path":[0],"field":"name","value":"Golang"}`,
+		"not a qa":      `{"op":"regenerate-id","path":[0,0]}`,
+		"bad move":      `{"op":"move","path":[0,0],"to":[1]}`,
+		"too deep":      `{"op":"delete","path":[0,0,0,0,0]}`,
+		"unknown op":    `{"op":"swap","path":[0]}`,
+	} {
+		data := dataset()
+		_, err := batch.Apply(data, read(t, ops))
+		assert.Error(t, err, name)
+		assert.Equal(t, dataset(), data, name)
+	}
+}
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	_, err := batch.Read(strings.NewReader(`{"op":"delete","path":[0]}` + "\n" + `{"op":"delete","paths":[0]}`))
+	assert.ErrorContains(t, err, "line 2")
+}
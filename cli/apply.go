@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/donderom/sqwat/batch"
+	"github.com/donderom/sqwat/splash"
+)
+
+func runApply(_ *splash.Session, args []string) (tea.Model, error) {
+	flags := newFlags("apply", "[flags] ops.jsonl dataset.json")
+	output := flags.String("o", "", "output file (default the dataset itself)")
+	dryRun := flags.Bool("dry-run", false, "report the ops without writing")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if flags.NArg() != 2 {
+		return nil, usage(flags)
+	}
+
+	opsPath, input := flags.Arg(0), flags.Arg(1)
+	if *output == "" {
+		*output = input
+	}
+
+	ops, err := readOps(opsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if *dryRun {
+		return nil, apply(ops, opsPath, input, *output, true)
+	}
+
+	// The output is read back and written under the lock for the
+	// edits of the TUI not to get lost
+	return nil, locked(*output, func() error {
+		return apply(ops, opsPath, input, *output, false)
+	})
+}
+
+func apply(ops []batch.Op, opsPath, input, output string, dryRun bool) error {
+	data, format, err := read(input)
+	if err != nil {
+		return err
+	}
+	old := data.Clone()
+
+	// Nothing is written unless all the ops succeed
+	results, err := batch.Apply(data, ops)
+	if err != nil {
+		return fmt.Errorf("%s: %w", opsPath, err)
+	}
+
+	w := info(output)
+	for _, r := range results {
+		fmt.Fprintln(w, r)
+	}
+
+	if dryRun {
+		fmt.Fprintf(w, "%d ops would be applied to %s\n", len(results), output)
+		return nil
+	}
+
+	if err = write(output, data, format); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Applied %d ops to %s\n", len(results), output)
+	return audited(output, old, data)
+}
+
+func readOps(path string) ([]batch.Op, error) {
+	if path == Stdio {
+		return batch.Read(os.Stdin)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	ops, err := batch.Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return ops, nil
+}
@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/donderom/sqwat/audit"
+	"github.com/donderom/sqwat/lockfile"
+	"github.com/donderom/sqwat/splash"
+	"github.com/donderom/sqwat/squad"
+)
+
+// Command is a headless subcommand. It may return a model to be run
+// in the TUI instead of finishing on its own, with the files it edits
+// held by the session.
+type Command struct {
+	Name string
+	Run  func(session *splash.Session, args []string) (tea.Model, error)
+}
+
+var commands = []Command{
+	{Name: "diff", Run: runDiff},
+	{Name: "merge", Run: runMerge},
+	{Name: "concat", Run: runConcat},
+	{Name: "split", Run: runSplit},
+	{Name: "leakage", Run: runLeakage},
+	{Name: "import", Run: runImport},
+	{Name: "export", Run: runExport},
+	{Name: "convert", Run: runConvert},
+	{Name: "apply-patch", Run: runApplyPatch},
+	{Name: "apply", Run: runApply},
+}
+
+var errUsage = errors.New("invalid usage")
+
+func Lookup(name string) (Command, bool) {
+	i := slices.IndexFunc(commands, func(c Command) bool { return c.Name == name })
+	if i == -1 {
+		return Command{}, false
+	}
+	return commands[i], true
+}
+
+func (c Command) Exec(session *splash.Session, args []string) (tea.Model, error) {
+	model, err := c.Run(session, args)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil, nil
+	}
+	return model, err
+}
+
+func newFlags(name, usage string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: sqwat %s %s\n", name, usage)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+func usage(flags *flag.FlagSet) error {
+	flags.Usage()
+	return errUsage
+}
+
+func load(path string) (*squad.SQuAD, error) {
+	data, _, err := read(path)
+	return data, err
+}
+
+// Stdio is the path of stdin when reading and stdout when writing.
+const Stdio = "-"
+
+func read(path string) (*squad.SQuAD, squad.Format, error) {
+	if path == Stdio {
+		return readFrom(os.Stdin, path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, squad.JSON, err
+	}
+
+	data, format, err := readFrom(file, path)
+	if err != nil {
+		_ = file.Close()
+		return nil, format, err
+	}
+
+	if err = file.Close(); err != nil {
+		return nil, format, err
+	}
+
+	return data, format, nil
+}
+
+func readFrom(r io.Reader, path string) (*squad.SQuAD, squad.Format, error) {
+	data, format, err := squad.Read(r)
+	if unmapped, ok := errors.AsType[squad.Unmapped](err); ok {
+		printSkipped(os.Stderr, path, unmapped)
+		err = nil
+	}
+	return data, format, err
+}
+
+func save(path string, data *squad.SQuAD) error {
+	return write(path, data, squad.JSON)
+}
+
+func write(path string, data *squad.SQuAD, format squad.Format) error {
+	return writeWith(path, func(w io.Writer) error { return data.Write(w, format) })
+}
+
+// writeWith writes path anew in a temporary file renamed over it, a
+// failed write leaves the file as it was.
+func writeWith(path string, f func(w io.Writer) error) error {
+	if path == Stdio {
+		return writeTo(os.Stdout, path, f)
+	}
+
+	mode := fs.FileMode(0o644)
+	if stat, err := os.Stat(path); err == nil {
+		mode = stat.Mode().Perm()
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+
+	w := squad.Compress(file, path)
+	err = writeTo(w, path, f)
+	if err == nil {
+		err = w.Close()
+	}
+	if err == nil {
+		err = file.Chmod(mode)
+	}
+	if err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return err
+	}
+
+	if err = file.Close(); err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+func writeTo(w io.Writer, path string, f func(w io.Writer) error) error {
+	err := f(w)
+	if unmapped, ok := errors.AsType[squad.Unmapped](err); ok {
+		printSkipped(os.Stderr, path, unmapped)
+		err = nil
+	}
+	return err
+}
+
+// locked runs f while no one else edits path, stdout is not for
+// anyone else to edit.
+func locked(path string, f func() error) error {
+	if path == Stdio {
+		return f()
+	}
+
+	lock, err := lockfile.Acquire(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	return errors.Join(f(), lock.Release())
+}
+
+// hold keeps others from editing paths while they are open in the TUI.
+func hold(session *splash.Session, paths ...string) error {
+	for i, path := range paths {
+		if path == Stdio || slices.Contains(paths[:i], path) {
+			continue
+		}
+		if err := session.Lock(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// audited logs the edits of data written to path, old is the data as
+// it was before them.
+func audited(path string, old, data *squad.SQuAD) error {
+	if path == Stdio {
+		return nil
+	}
+
+	entries, err := audit.Changes(old, data, audit.User())
+	if err != nil || len(entries) == 0 {
+		return err
+	}
+	return audit.Append(path, entries...)
+}
+
+// info is where to report on writing to output, stderr keeps the data
+// written to stdout clean.
+func info(output string) io.Writer {
+	if output == Stdio {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+func printSkipped(w io.Writer, path string, unmapped squad.Unmapped) {
+	for _, s := range unmapped {
+		fmt.Fprintf(w, "%s: skipped %s\n", path, s)
+	}
+	fmt.Fprintf(w, "%s: %s\n", path, unmapped)
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/donderom/sqwat/merge"
+	"github.com/donderom/sqwat/splash"
+	"github.com/donderom/sqwat/squad"
+)
+
+func runConcat(_ *splash.Session, args []string) (tea.Model, error) {
+	flags := newFlags("concat", "[flags] a.json b.json ...")
+	output := flags.String("o", "", "output file (required)")
+	ids := flags.String("ids", "reject", "colliding question IDs: reject or regenerate")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if flags.NArg() < 1 || *output == "" {
+		return nil, usage(flags)
+	}
+
+	var policy merge.IDPolicy
+	switch *ids {
+	case "reject":
+		policy = merge.RejectIDs
+	case "regenerate":
+		policy = merge.RegenerateIDs
+	default:
+		return nil, usage(flags)
+	}
+
+	sets := make([]*squad.SQuAD, flags.NArg())
+	for i, path := range flags.Args() {
+		data, err := load(path)
+		if err != nil {
+			return nil, err
+		}
+		sets[i] = data
+	}
+
+	data, notes, err := merge.Concat(sets, flags.Args(), policy)
+	printNotes(info(*output), notes)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, save(*output, data)
+}
+
+func printNotes(w io.Writer, notes []merge.Note) {
+	counts := make(map[merge.NoteKind]int)
+	for _, n := range notes {
+		fmt.Fprintf(w, "%s: %s\n", n.Kind, n.Message)
+		counts[n.Kind]++
+	}
+
+	kinds := []merge.NoteKind{
+		merge.MergedArticle,
+		merge.CollapsedParagraph,
+		merge.DroppedDuplicate,
+		merge.RenamedID,
+		merge.CollidingID,
+	}
+	for _, kind := range kinds {
+		if counts[kind] > 0 {
+			fmt.Fprintf(w, "%d %s\n", counts[kind], kind)
+		}
+	}
+}
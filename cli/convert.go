@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/donderom/sqwat/splash"
+	"github.com/donderom/sqwat/squad"
+)
+
+func runConvert(_ *splash.Session, args []string) (tea.Model, error) {
+	flags := newFlags("convert", "[flags] input output")
+	format := flags.String("format", "", "output format (by default jsonl for .jsonl outputs and json otherwise)")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if flags.NArg() != 2 {
+		return nil, usage(flags)
+	}
+
+	input, output := flags.Arg(0), flags.Arg(1)
+
+	f := outputFormat(output)
+	if *format != "" {
+		var ok bool
+		if f, ok = squad.ParseFormat(*format); !ok {
+			return nil, usage(flags)
+		}
+	}
+
+	data, err := load(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, write(output, data, f)
+}
+
+// outputFormat guesses the format by the extension, gzipped or not.
+func outputFormat(path string) squad.Format {
+	if squad.IsCompressed(path) {
+		path = strings.TrimSuffix(path, filepath.Ext(path))
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".jsonl") {
+		return squad.JSONL
+	}
+	return squad.JSON
+}
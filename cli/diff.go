@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/donderom/sqwat/diff"
+	"github.com/donderom/sqwat/diffview"
+	"github.com/donderom/sqwat/splash"
+)
+
+func runDiff(_ *splash.Session, args []string) (tea.Model, error) {
+	flags := newFlags("diff", "[flags] old.json new.json")
+	tui := flags.Bool("tui", false, "browse the changes in the TUI")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if flags.NArg() != 2 {
+		return nil, usage(flags)
+	}
+
+	oldPath, newPath := flags.Arg(0), flags.Arg(1)
+
+	old, err := load(oldPath)
+	if err != nil {
+		return nil, err
+	}
+
+	new, err := load(newPath)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := diff.Compare(old, new)
+
+	if *tui {
+		title := fmt.Sprintf("%s → %s", oldPath, newPath)
+		return diffview.New(title, changes).Standalone(), nil
+	}
+
+	printChanges(os.Stdout, changes)
+	return nil, nil
+}
+
+func printChanges(w io.Writer, changes []diff.Change) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No differences")
+		return
+	}
+
+	for _, c := range changes {
+		fmt.Fprintf(w, "%s %s %s\n", c.Kind.Symbol(), c.Type, c.Location())
+		for _, f := range c.Fields {
+			fmt.Fprintf(w, "    %s: %s\n", f.Name, diff.Inline(f.Edits))
+		}
+	}
+
+	fmt.Fprintln(w, diff.Summarize(changes))
+}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/donderom/sqwat/export"
+	"github.com/donderom/sqwat/splash"
+	"github.com/donderom/sqwat/squad"
+)
+
+func runExport(_ *splash.Session, args []string) (tea.Model, error) {
+	flags := newFlags("export", "[flags] dataset.json")
+	format := flags.String("format", "", "output format: json, jsonl, huggingface, labelstudio, doccano, prodigy, beir, dpr, bio-conll or bio-jsonl")
+	output := flags.String("o", "", "output file or directory for beir (required)")
+	ids := flags.String("ids", "index", "beir and dpr paragraph IDs: index or hash")
+	negatives := flags.Int("k", 5, "dpr hard negatives per question")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if flags.NArg() != 1 || *output == "" {
+		return nil, usage(flags)
+	}
+
+	data, err := load(flags.Arg(0))
+	if err != nil {
+		return nil, err
+	}
+
+	switch *format {
+	case "labelstudio":
+		if err = write(*output, data, squad.LabelStudio); err != nil {
+			return nil, err
+		}
+
+		// There is no next to stdout
+		if *output == Stdio {
+			return nil, nil
+		}
+
+		config := strings.TrimSuffix(*output, filepath.Ext(*output)) + ".xml"
+		return nil, os.WriteFile(config, []byte(squad.LabelStudioConfig), 0o644)
+
+	case "beir":
+		scheme, ok := idScheme(*ids)
+		if !ok || *output == Stdio {
+			return nil, usage(flags)
+		}
+
+		stats, err := export.BEIR(*output, data, scheme)
+		if err != nil {
+			return nil, err
+		}
+
+		printBEIR(info(*output), stats)
+		return nil, nil
+
+	case "dpr":
+		scheme, ok := idScheme(*ids)
+		if !ok || *negatives < 0 {
+			return nil, usage(flags)
+		}
+
+		var stats export.DPRStats
+		err := writeWith(*output, func(w io.Writer) (err error) {
+			stats, err = export.DPR(w, data, *negatives, scheme)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		printDPR(info(*output), stats, *negatives)
+		return nil, nil
+
+	case "bio-conll":
+		return nil, writeWith(*output, func(w io.Writer) error {
+			return export.BIO(w, data, export.CoNLL)
+		})
+
+	case "bio-jsonl":
+		return nil, writeWith(*output, func(w io.Writer) error {
+			return export.BIO(w, data, export.BIOJSONL)
+		})
+	}
+
+	if f, ok := squad.ParseFormat(*format); ok {
+		return nil, write(*output, data, f)
+	}
+
+	return nil, usage(flags)
+}
+
+func printBEIR(w io.Writer, stats export.BEIRStats) {
+	fmt.Fprintf(w, "Exported %d documents and %d queries\n", stats.Documents, stats.Queries)
+	if stats.Impossible > 0 {
+		fmt.Fprintf(w, "%d impossible questions left out\n", stats.Impossible)
+	}
+	if stats.Duplicates > 0 {
+		fmt.Fprintf(w, "%d questions with duplicate IDs left out\n", stats.Duplicates)
+	}
+}
+
+func printDPR(w io.Writer, stats export.DPRStats, k int) {
+	fmt.Fprintf(w, "Exported %d questions\n", stats.Questions)
+	if stats.Impossible > 0 {
+		fmt.Fprintf(w, "%d impossible questions left out\n", stats.Impossible)
+	}
+	if stats.Short > 0 {
+		fmt.Fprintf(w, "%d questions with fewer than %d hard negatives\n", stats.Short, k)
+	}
+}
+
+func idScheme(ids string) (export.IDScheme, bool) {
+	switch ids {
+	case "index":
+		return export.IndexIDs, true
+	case "hash":
+		return export.HashIDs, true
+	}
+	return export.IndexIDs, false
+}
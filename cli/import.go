@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/donderom/sqwat/importview"
+	"github.com/donderom/sqwat/splash"
+	"github.com/donderom/sqwat/tabular"
+)
+
+func runImport(session *splash.Session, args []string) (tea.Model, error) {
+	flags := newFlags("import", "[flags] questions.csv dataset.json")
+	columns := flags.String("columns", "", "column headers, e.g. title=Topic,context=Passage,question=Q,answer=A,id=ID")
+	sep := flags.String("sep", "", "separator: comma or tab (by file extension by default)")
+	tui := flags.Bool("tui", false, "review ambiguous and missing answers in the TUI")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if flags.NArg() != 2 {
+		return nil, usage(flags)
+	}
+
+	csvPath, path := flags.Arg(0), flags.Arg(1)
+
+	separator := tabular.Separator(csvPath)
+	switch *sep {
+	case "":
+	case "comma":
+		separator = ','
+	case "tab":
+		separator = '\t'
+	default:
+		return nil, usage(flags)
+	}
+
+	cols, err := tabular.ParseColumns(*columns)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(csvPath)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := tabular.Read(file, separator, cols)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	if err = file.Close(); err != nil {
+		return nil, err
+	}
+
+	if *tui {
+		if err = hold(session, path); err != nil {
+			return nil, err
+		}
+
+		data, format, err := read(path)
+		if err != nil {
+			return nil, err
+		}
+
+		dataset := splash.NewDataset(session, data, path, format)
+		return importview.New(csvPath, result, data, path, dataset).Standalone(), nil
+	}
+
+	return nil, locked(path, func() error {
+		data, format, err := read(path)
+		if err != nil {
+			return err
+		}
+		old := data.Clone()
+
+		renamed := tabular.Append(data, result.Data)
+		if err = write(path, data, format); err != nil {
+			return err
+		}
+
+		printReviews(info(path), result, renamed)
+		return audited(path, old, data)
+	})
+}
+
+func printReviews(w io.Writer, result tabular.Result, renamed int) {
+	for _, r := range result.Reviews {
+		fmt.Fprintf(w, "row %d: %s\n    %s\n    answer: %q\n", r.Row, r.Problem, r.QA.Question, r.Answer)
+	}
+
+	fmt.Fprintf(w, "Imported %d of %d rows", result.Rows-len(result.Reviews), result.Rows)
+	if renamed > 0 {
+		fmt.Fprintf(w, ", %d with regenerated IDs", renamed)
+	}
+	fmt.Fprintln(w)
+
+	if len(result.Reviews) > 0 {
+		fmt.Fprintf(w, "%d rows not imported, use -tui to review them\n", len(result.Reviews))
+	}
+}
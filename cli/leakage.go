@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/donderom/sqwat/leakage"
+	"github.com/donderom/sqwat/leakview"
+	"github.com/donderom/sqwat/splash"
+)
+
+func runLeakage(session *splash.Session, args []string) (tea.Model, error) {
+	flags := newFlags("leakage", "[flags] train.json dev.json")
+	n := flags.Int("n", leakage.DefaultOptions.N, "size of word n-grams compared")
+	threshold := flags.Float64("threshold", leakage.DefaultOptions.Threshold,
+		"minimal Jaccard similarity of near-duplicate contexts")
+	tui := flags.Bool("tui", false, "browse and fix the hits in the TUI")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if flags.NArg() != 2 || *n < 1 {
+		return nil, usage(flags)
+	}
+
+	leftPath, rightPath := flags.Arg(0), flags.Arg(1)
+	if *tui {
+		if err := hold(session, leftPath, rightPath); err != nil {
+			return nil, err
+		}
+	}
+
+	left, leftFormat, err := read(leftPath)
+	if err != nil {
+		return nil, err
+	}
+
+	right, rightFormat, err := read(rightPath)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := leakage.Options{N: *n, Threshold: *threshold}
+	if *tui {
+		return leakview.New(
+			leakview.File{Name: leftPath, Data: left, Dataset: splash.NewDataset(session, left, leftPath, leftFormat)},
+			leakview.File{Name: rightPath, Data: right, Dataset: splash.NewDataset(session, right, rightPath, rightFormat)},
+			opts,
+		), nil
+	}
+
+	hits := leakage.Detect(left, right, opts)
+	printHits(os.Stdout, leftPath, rightPath, hits)
+	return nil, nil
+}
+
+func printHits(w io.Writer, leftPath, rightPath string, hits []leakage.Hit) {
+	counts := make(map[leakage.Kind]int)
+	for _, h := range hits {
+		counts[h.Kind]++
+		fmt.Fprintf(w, "%s\n    %s: %s\n    %s: %s\n",
+			h.Description(), leftPath, h.Left, rightPath, h.Right)
+	}
+
+	fmt.Fprintf(w, "%d identical contexts, %d similar contexts, %d duplicate questions\n",
+		counts[leakage.ExactContext], counts[leakage.NearContext], counts[leakage.DupQuestion])
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/donderom/sqwat/merge"
+	"github.com/donderom/sqwat/mergeview"
+	"github.com/donderom/sqwat/splash"
+)
+
+func runMerge(_ *splash.Session, args []string) (tea.Model, error) {
+	flags := newFlags("merge", "[flags] base.json ours.json theirs.json")
+	output := flags.String("o", "", "output file (required)")
+	report := flags.String("report", "", "conflict report file (default <output>.conflicts)")
+	tui := flags.Bool("tui", false, "resolve the conflicts in the TUI")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if flags.NArg() != 3 || *output == "" {
+		return nil, usage(flags)
+	}
+
+	if *report == "" {
+		*report = *output + ".conflicts"
+		if *output == Stdio {
+			*report = "merge.conflicts"
+		}
+	}
+
+	base, err := load(flags.Arg(0))
+	if err != nil {
+		return nil, err
+	}
+
+	ours, err := load(flags.Arg(1))
+	if err != nil {
+		return nil, err
+	}
+
+	theirs, err := load(flags.Arg(2))
+	if err != nil {
+		return nil, err
+	}
+
+	result := merge.Merge(base, ours, theirs, nil)
+
+	if *tui && len(result.Conflicts) > 0 {
+		done := func(resolutions merge.Resolutions) tea.Cmd {
+			return func() tea.Msg {
+				result := merge.Merge(base, ours, theirs, resolutions)
+				if err := save(*output, result.Data); err != nil {
+					return mergeview.Resolved{Err: err}
+				}
+				if err := writeReport(*report, result.Conflicts, resolutions); err != nil {
+					return mergeview.Resolved{Err: err}
+				}
+				return tea.Quit()
+			}
+		}
+		return mergeview.New(*output, result.Conflicts, done).Standalone(), nil
+	}
+
+	if err := save(*output, result.Data); err != nil {
+		return nil, err
+	}
+
+	if len(result.Conflicts) == 0 {
+		fmt.Fprintf(info(*output), "Merged into %s without conflicts\n", *output)
+		return nil, nil
+	}
+
+	if err := writeReport(*report, result.Conflicts, nil); err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(info(*output), "Merged into %s with %d conflicts resolved as ours, see %s\n",
+		*output, len(result.Conflicts), *report)
+	return nil, nil
+}
+
+// writeReport lists the conflicts with the sides kept, ours unless
+// resolved otherwise.
+func writeReport(path string, conflicts []merge.Conflict, resolutions merge.Resolutions) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	writeConflicts(file, conflicts, resolutions)
+	return file.Close()
+}
+
+func writeConflicts(w io.Writer, conflicts []merge.Conflict, resolutions merge.Resolutions) {
+	for _, c := range conflicts {
+		kept := merge.Ours
+		if resolutions[c.Key] == merge.Theirs {
+			kept = merge.Theirs
+		}
+
+		fmt.Fprintf(w, "%s: %s %s\n", c.Type, c.Field, c.Where)
+		fmt.Fprintf(w, "    base:   %s\n", c.Base)
+		fmt.Fprintf(w, "    ours:   %s\n", c.Ours)
+		fmt.Fprintf(w, "    theirs: %s\n", c.Theirs)
+		fmt.Fprintf(w, "    kept:   %s\n", kept)
+	}
+}
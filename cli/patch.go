@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"encoding/json/v2"
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/donderom/sqwat/patch"
+	"github.com/donderom/sqwat/splash"
+	"github.com/donderom/sqwat/squad"
+)
+
+func runApplyPatch(_ *splash.Session, args []string) (tea.Model, error) {
+	flags := newFlags("apply-patch", "[flags] patch.json dataset.json")
+	output := flags.String("o", "", "output file (default the dataset itself)")
+	check := flags.Bool("check", false, "only check that the patch applies")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if flags.NArg() != 2 {
+		return nil, usage(flags)
+	}
+
+	patchPath, input := flags.Arg(0), flags.Arg(1)
+	if *output == "" {
+		*output = input
+	}
+
+	p, err := readPatch(patchPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if *check {
+		return nil, applyTo(p, patchPath, input, *output, true)
+	}
+
+	return nil, locked(*output, func() error {
+		return applyTo(p, patchPath, input, *output, false)
+	})
+}
+
+func applyTo(p patch.Patch, patchPath, input, output string, check bool) error {
+	data, format, err := read(input)
+	if err != nil {
+		return err
+	}
+
+	// The tests of the patch are checked along the way, nothing is
+	// written unless all of them pass
+	result, err := applyPatch(p, data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", patchPath, err)
+	}
+
+	if check {
+		fmt.Fprintf(info(output), "%s applies to %s (%d operations)\n", patchPath, input, len(p))
+		return nil
+	}
+
+	if err = write(output, result, format); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(info(output), "Applied %d operations of %s to %s\n", len(p), patchPath, output)
+	return audited(output, data, result)
+}
+
+func readPatch(path string) (patch.Patch, error) {
+	if path == Stdio {
+		return patch.Read(os.Stdin)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	p, err := patch.Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+func applyPatch(p patch.Patch, data *squad.SQuAD) (*squad.SQuAD, error) {
+	doc, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if doc, err = p.Apply(doc); err != nil {
+		return nil, err
+	}
+
+	var result squad.SQuAD
+	if err = json.Unmarshal(doc, &result); err != nil {
+		return nil, fmt.Errorf("patched data is not in the SQuAD format: %w", err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/donderom/sqwat/splash"
+	"github.com/donderom/sqwat/split"
+)
+
+func runSplit(_ *splash.Session, args []string) (tea.Model, error) {
+	flags := newFlags("split", "[flags] dataset.json")
+	ratios := flags.String("ratio", "", "comma-separated relative sizes, e.g. 0.8,0.1,0.1")
+	counts := flags.String("count", "", "comma-separated sizes in questions, e.g. 1000,200")
+	names := flags.String("names", "train,dev,test", "comma-separated partition names")
+	stratify := flags.Bool("stratify", false, "keep the ratio of impossible questions per partition")
+	seed := flags.Uint64("seed", 1, "random seed")
+	dir := flags.String("o", ".", "output directory")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if flags.NArg() != 1 || (*ratios == "") == (*counts == "") {
+		return nil, usage(flags)
+	}
+
+	opts := split.Options{Stratify: *stratify, Seed: *seed}
+	var err error
+	var parts int
+
+	if *ratios != "" {
+		opts.Ratios, err = parseList(*ratios, func(s string) (float64, error) {
+			return strconv.ParseFloat(s, 64)
+		})
+		parts = len(opts.Ratios)
+	} else {
+		opts.Counts, err = parseList(*counts, strconv.Atoi)
+		parts = len(opts.Counts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	opts.Names = strings.Split(*names, ",")
+	if len(opts.Names) > parts {
+		opts.Names = opts.Names[:parts]
+	}
+
+	data, err := load(flags.Arg(0))
+	if err != nil {
+		return nil, err
+	}
+
+	partitions, err := split.Split(data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range partitions {
+		path := filepath.Join(*dir, p.Name+".json")
+		if err := save(path, p.Data); err != nil {
+			return nil, err
+		}
+
+		stats := split.Count(p.Data)
+		fmt.Fprintf(info(path), "%s: %d articles, %d questions (%d impossible) → %s\n",
+			p.Name, stats.Articles, stats.Questions, stats.Impossible, path)
+	}
+
+	return nil, nil
+}
+
+func parseList[T any](s string, parse func(string) (T, error)) ([]T, error) {
+	fields := strings.Split(s, ",")
+	result := make([]T, len(fields))
+	for i, f := range fields {
+		v, err := parse(strings.TrimSpace(f))
+		if err != nil {
+			return nil, err
+		}
+		result[i] = v
+	}
+	return result, nil
+}
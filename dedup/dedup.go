@@ -0,0 +1,254 @@
+package dedup
+
+import (
+	"cmp"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+
+	"github.com/donderom/sqwat/squad"
+	"github.com/donderom/sqwat/text"
+	"github.com/donderom/sqwat/validation"
+)
+
+type Options struct {
+	// Size of character n-grams
+	N int
+	// Number of MinHash bands and rows per band, their product is the
+	// signature length. More bands find less similar candidates.
+	Bands int
+	Rows  int
+	// Minimal Jaccard similarity of questions in a cluster
+	Threshold float64
+}
+
+var DefaultOptions = Options{N: 3, Bands: 16, Rows: 4, Threshold: 0.7}
+
+type Member struct {
+	Path    validation.Path
+	Article string
+	Context string
+	QA      squad.QA
+}
+
+var _ list.DefaultItem = Member{}
+
+func (m Member) Title() string { return m.QA.Question }
+
+func (m Member) Description() string {
+	return fmt.Sprintf("%q, paragraph %d, question %d",
+		m.Article,
+		m.Path.To(validation.Paragraph)+1,
+		m.Path.To(validation.Question)+1,
+	)
+}
+
+func (m Member) FilterValue() string { return m.QA.Question }
+
+type Cluster struct {
+	Members []Member
+	// The lowest similarity of a member to its closest neighbour
+	Similarity float64
+}
+
+var _ list.DefaultItem = Cluster{}
+
+func (c Cluster) Title() string { return c.Members[0].QA.Question }
+
+func (c Cluster) Description() string {
+	return fmt.Sprintf("%d similar questions (%.0f%%)", len(c.Members), c.Similarity*100)
+}
+
+func (c Cluster) FilterValue() string { return c.Title() }
+
+// IDs returns the sorted IDs of the cluster members.
+func (c Cluster) IDs() []string {
+	ids := make([]string, len(c.Members))
+	for i, m := range c.Members {
+		ids[i] = m.QA.Id
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// Detect clusters near-duplicate questions across the whole dataset.
+// Candidates are found with MinHash LSH over character shingles and
+// confirmed by their exact Jaccard similarity.
+func Detect(s *squad.SQuAD, opts Options) []Cluster {
+	var members []Member
+	var shingles []map[string]struct{}
+
+	for i, a := range s.Articles {
+		for j, p := range a.Paragraphs {
+			for k, qa := range p.QAs {
+				sh := Shingles(qa.Question, opts.N)
+				if len(sh) == 0 {
+					continue
+				}
+
+				members = append(members, Member{
+					Path: validation.Path{
+						validation.Article:   i,
+						validation.Paragraph: j,
+						validation.Question:  k,
+					},
+					Article: a.Name,
+					Context: p.Context,
+					QA:      qa,
+				})
+				shingles = append(shingles, sh)
+			}
+		}
+	}
+
+	seeds := seeds(opts.Bands * opts.Rows)
+	buckets := make(map[string][]int)
+
+	for i, sh := range shingles {
+		signature := minhash(sh, seeds)
+		for b := range opts.Bands {
+			band := signature[b*opts.Rows : (b+1)*opts.Rows]
+			key := fmt.Sprint(b, band)
+			buckets[key] = append(buckets[key], i)
+		}
+	}
+
+	parent := make([]int, len(members))
+	for i := range parent {
+		parent[i] = i
+	}
+	best := make([]float64, len(members))
+	checked := make(map[[2]int]struct{})
+
+	for _, bucket := range buckets {
+		for x := range bucket {
+			for y := x + 1; y < len(bucket); y++ {
+				pair := [2]int{bucket[x], bucket[y]}
+				if _, ok := checked[pair]; ok {
+					continue
+				}
+				checked[pair] = struct{}{}
+
+				similarity := text.Jaccard(shingles[pair[0]], shingles[pair[1]])
+				if similarity >= opts.Threshold {
+					union(parent, pair[0], pair[1])
+					best[pair[0]] = max(best[pair[0]], similarity)
+					best[pair[1]] = max(best[pair[1]], similarity)
+				}
+			}
+		}
+	}
+
+	groups := make(map[int][]int)
+	for i := range members {
+		root := find(parent, i)
+		groups[root] = append(groups[root], i)
+	}
+
+	var clusters []Cluster
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+
+		slices.Sort(group)
+		cluster := Cluster{Similarity: 1}
+		for _, i := range group {
+			cluster.Members = append(cluster.Members, members[i])
+			cluster.Similarity = min(cluster.Similarity, best[i])
+		}
+		clusters = append(clusters, cluster)
+	}
+
+	slices.SortFunc(clusters, func(a, b Cluster) int {
+		return cmp.Or(
+			cmp.Compare(b.Similarity, a.Similarity),
+			comparePaths(a.Members[0].Path, b.Members[0].Path),
+		)
+	})
+
+	return clusters
+}
+
+// Shingles returns the character n-grams of the normalized question.
+func Shingles(question string, n int) map[string]struct{} {
+	normalized := []rune(strings.Join(text.Words(question), " "))
+	shingles := make(map[string]struct{})
+
+	if len(normalized) == 0 {
+		return shingles
+	}
+
+	if len(normalized) < n {
+		shingles[string(normalized)] = struct{}{}
+		return shingles
+	}
+
+	for i := 0; i+n <= len(normalized); i++ {
+		shingles[string(normalized[i:i+n])] = struct{}{}
+	}
+
+	return shingles
+}
+
+func minhash(shingles map[string]struct{}, seeds []uint64) []uint64 {
+	signature := make([]uint64, len(seeds))
+	for i := range signature {
+		signature[i] = ^uint64(0)
+	}
+
+	for s := range shingles {
+		h := fnv.New64a()
+		h.Write([]byte(s))
+		base := h.Sum64()
+
+		for i, seed := range seeds {
+			signature[i] = min(signature[i], mix(base^seed))
+		}
+	}
+
+	return signature
+}
+
+func seeds(n int) []uint64 {
+	result := make([]uint64, n)
+	var state uint64
+	for i := range result {
+		state += 0x9e3779b97f4a7c15
+		result[i] = mix(state)
+	}
+	return result
+}
+
+// mix is the splitmix64 finalizer
+func mix(x uint64) uint64 {
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+func find(parent []int, i int) int {
+	for parent[i] != i {
+		parent[i] = parent[parent[i]]
+		i = parent[i]
+	}
+	return i
+}
+
+func union(parent []int, a, b int) {
+	ra, rb := find(parent, a), find(parent, b)
+	if ra != rb {
+		parent[max(ra, rb)] = min(ra, rb)
+	}
+}
+
+func comparePaths(a, b validation.Path) int {
+	return cmp.Or(
+		cmp.Compare(a.To(validation.Article), b.To(validation.Article)),
+		cmp.Compare(a.To(validation.Paragraph), b.To(validation.Paragraph)),
+		cmp.Compare(a.To(validation.Question), b.To(validation.Question)),
+	)
+}
@@ -0,0 +1,123 @@
+package dedup_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/donderom/sqwat/dedup"
+	"github.com/donderom/sqwat/squad"
+	"github.com/donderom/sqwat/validation"
+)
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	clusters := dedup.Detect(data(), dedup.DefaultOptions)
+	require.Len(t, clusters, 1)
+
+	cluster := clusters[0]
+	assert.Equal(t, []string{"1", "2", "4"}, cluster.IDs())
+	assert.Greater(t, cluster.Similarity, 0.7)
+	assert.Equal(t, validation.Path{
+		validation.Article:   1,
+		validation.Paragraph: 0,
+		validation.Question:  0,
+	}, cluster.Members[2].Path)
+}
+
+func TestDetectNothing(t *testing.T) {
+	t.Parallel()
+
+	s := &squad.SQuAD{Articles: []squad.Article{{
+		Name: "Go",
+		Paragraphs: []squad.Paragraph{{
+			Context: "Go was designed at Google.",
+			QAs: []squad.QA{
+				{Id: "1", Question: "Who designed Go?"},
+				{Id: "2", Question: "When was the first release?"},
+				{Id: "3", Question: "?"},
+			},
+		}},
+	}}}
+
+	assert.Empty(t, dedup.Detect(s, dedup.DefaultOptions))
+}
+
+func TestKeep(t *testing.T) {
+	t.Parallel()
+
+	s := data()
+	cluster := dedup.Detect(s, dedup.DefaultOptions)[0]
+
+	removals := dedup.Keep(s, cluster, 1)
+	require.Len(t, removals, 2)
+
+	assert.Equal(t, []string{"2", "3"}, ids(s.Articles[0].Paragraphs[0].QAs))
+	assert.Empty(t, s.Articles[1].Paragraphs[0].QAs)
+
+	dedup.Restore(s, removals)
+	assert.Equal(t, data(), s)
+}
+
+func TestIntended(t *testing.T) {
+	t.Parallel()
+
+	clusters := dedup.Detect(data(), dedup.DefaultOptions)
+	path := filepath.Join(t.TempDir(), "data.json.intended")
+
+	intended, err := dedup.LoadIntended(path)
+	require.NoError(t, err)
+	assert.Empty(t, intended)
+
+	intended = intended.Mark(clusters[0])
+	require.NoError(t, intended.Save(path))
+
+	intended, err = dedup.LoadIntended(path)
+	require.NoError(t, err)
+	assert.Equal(t, dedup.Intended{{"1", "2", "4"}}, intended)
+	assert.Empty(t, intended.Filter(clusters))
+
+	// Clusters with unmarked questions show up again
+	cluster := clusters[0]
+	cluster.Members = append(cluster.Members, dedup.Member{QA: squad.QA{Id: "5"}})
+	assert.False(t, intended.Covers(cluster))
+}
+
+func ids(qas []squad.QA) []string {
+	var result []string
+	for _, qa := range qas {
+		result = append(result, qa.Id)
+	}
+	return result
+}
+
+func data() *squad.SQuAD {
+	return &squad.SQuAD{
+		Version: "v2.0",
+		Articles: []squad.Article{
+			{
+				Name: "Go",
+				Paragraphs: []squad.Paragraph{{
+					Context: "Go was designed at Google in 2007.",
+					QAs: []squad.QA{
+						{Id: "1", Question: "Who designed the Go language?"},
+						{Id: "2", Question: "Who designed the Go language ?"},
+						{Id: "3", Question: "When was Go designed?"},
+					},
+				}},
+			},
+			{
+				Name: "Golang",
+				Paragraphs: []squad.Paragraph{{
+					Context: "The Go language was created at Google.",
+					QAs: []squad.QA{
+						{Id: "4", Question: "Who designed Go language?"},
+					},
+				}},
+			},
+		},
+	}
+}
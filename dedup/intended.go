@@ -0,0 +1,85 @@
+package dedup
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Intended holds the groups of question IDs marked as intentionally
+// similar, one group per line of a sidecar file next to the dataset.
+type Intended [][]string
+
+func SidecarPath(filename string) string {
+	return filename + ".intended"
+}
+
+func LoadIntended(path string) (Intended, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var intended Intended
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			intended = append(intended, strings.Split(line, ","))
+		}
+	}
+
+	return intended, scanner.Err()
+}
+
+func (in Intended) Save(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(file)
+	for _, ids := range in {
+		if _, err = w.WriteString(strings.Join(ids, ",") + "\n"); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// Mark adds the IDs of the cluster as a new group.
+func (in Intended) Mark(c Cluster) Intended {
+	return append(in, c.IDs())
+}
+
+// Covers tells if all questions of the cluster were marked together.
+func (in Intended) Covers(c Cluster) bool {
+	ids := c.IDs()
+	return slices.ContainsFunc(in, func(group []string) bool {
+		for _, id := range ids {
+			if !slices.Contains(group, id) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Filter returns the clusters not covered by marked groups.
+func (in Intended) Filter(clusters []Cluster) []Cluster {
+	return slices.DeleteFunc(clusters, in.Covers)
+}
@@ -0,0 +1,55 @@
+package dedup
+
+import (
+	"slices"
+
+	"github.com/donderom/sqwat/squad"
+	"github.com/donderom/sqwat/validation"
+)
+
+// Removal is a question deleted while resolving a cluster.
+type Removal struct {
+	Path validation.Path
+	QA   squad.QA
+}
+
+// Keep deletes every member of the cluster except the one at index.
+func Keep(s *squad.SQuAD, c Cluster, index int) []Removal {
+	var members []Member
+	for i, m := range c.Members {
+		if i != index {
+			members = append(members, m)
+		}
+	}
+	return Remove(s, members)
+}
+
+// Remove deletes the questions of members from s. Questions are removed
+// from the last one so that the paths of the others stay valid.
+func Remove(s *squad.SQuAD, members []Member) []Removal {
+	removals := make([]Removal, len(members))
+	for i, m := range members {
+		removals[i] = Removal{Path: m.Path, QA: m.QA}
+	}
+
+	slices.SortFunc(removals, func(a, b Removal) int {
+		return comparePaths(b.Path, a.Path)
+	})
+
+	for _, r := range removals {
+		paragraph(s, r.Path).Remove(r.Path.To(validation.Question))
+	}
+
+	return removals
+}
+
+// Restore puts the removed questions back in place.
+func Restore(s *squad.SQuAD, removals []Removal) {
+	for _, r := range slices.Backward(removals) {
+		paragraph(s, r.Path).Insert(r.Path.To(validation.Question), r.QA)
+	}
+}
+
+func paragraph(s *squad.SQuAD, path validation.Path) *squad.Paragraph {
+	return s.At(path.To(validation.Article)).At(path.To(validation.Paragraph))
+}
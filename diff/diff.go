@@ -0,0 +1,348 @@
+package diff
+
+import (
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+
+	"github.com/donderom/sqwat/squad"
+	"github.com/donderom/sqwat/style"
+	"github.com/donderom/sqwat/text"
+	"github.com/donderom/sqwat/validation"
+)
+
+type Kind uint8
+
+const (
+	Added Kind = iota
+	Removed
+	Modified
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Modified:
+		return "modified"
+	}
+	return "unknown"
+}
+
+func (k Kind) Symbol() string {
+	switch k {
+	case Added:
+		return "+"
+	case Removed:
+		return "-"
+	}
+	return "~"
+}
+
+type Field struct {
+	Name  string
+	Edits []text.Edit
+}
+
+type Change struct {
+	Kind    Kind
+	Type    validation.ItemType
+	OldPath validation.Path
+	NewPath validation.Path
+	Article string
+	Label   string
+	Fields  []Field
+}
+
+var _ list.DefaultItem = Change{}
+
+func (c Change) Title() string { return c.Kind.Symbol() + " " + c.Label }
+
+func (c Change) Description() string {
+	return fmt.Sprintf("%s %s %s", c.Type, c.Kind, c.Location())
+}
+
+func (c Change) FilterValue() string { return c.Label }
+
+// Path returns the location in the new dataset or in the old one
+// for removed items.
+func (c Change) Path() validation.Path {
+	if c.Kind == Removed {
+		return c.OldPath
+	}
+	return c.NewPath
+}
+
+func (c Change) Location() string {
+	path := c.Path()
+	location := strconv.Quote(c.Article)
+
+	if c.Type >= validation.Paragraph {
+		location += fmt.Sprintf(", paragraph %d", path.To(validation.Paragraph)+1)
+	}
+
+	if c.Type >= validation.Question {
+		location += fmt.Sprintf(", question %d", path.To(validation.Question)+1)
+	}
+
+	return "in " + location
+}
+
+func (c Change) Render() string {
+	lines := make([]string, 0, len(c.Fields))
+	for _, f := range c.Fields {
+		lines = append(lines, style.Faint.Render(f.Name+":")+" "+Inline(f.Edits))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Inline renders edits in the word diff notation.
+func Inline(edits []text.Edit) string {
+	var s strings.Builder
+	for _, e := range edits {
+		switch e.Op {
+		case text.Equal:
+			s.WriteString(e.Text)
+		case text.Insert:
+			s.WriteString(style.Added.Render("{+" + e.Text + "+}"))
+		case text.Delete:
+			s.WriteString(style.Removed.Render("[-" + e.Text + "-]"))
+		}
+	}
+	return s.String()
+}
+
+type Stats map[Kind]int
+
+func Summarize(changes []Change) Stats {
+	stats := make(Stats, 3)
+	for _, c := range changes {
+		stats[c.Kind]++
+	}
+	return stats
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("%d added, %d removed, %d modified",
+		s[Added], s[Removed], s[Modified])
+}
+
+func Compare(old, new *squad.SQuAD) []Change {
+	var changes []Change
+
+	for _, pair := range MatchArticles(old.Articles, new.Articles) {
+		switch {
+		case pair.Removed():
+			a := old.Articles[pair.Old]
+			changes = append(changes, Change{
+				Kind:    Removed,
+				Type:    validation.Article,
+				OldPath: validation.Path{validation.Article: pair.Old},
+				Article: a.Name,
+				Label:   a.Name,
+				Fields:  []Field{removed("title", a.Name)},
+			})
+
+		case pair.Added():
+			a := new.Articles[pair.New]
+			changes = append(changes, Change{
+				Kind:    Added,
+				Type:    validation.Article,
+				NewPath: validation.Path{validation.Article: pair.New},
+				Article: a.Name,
+				Label:   a.Name,
+				Fields:  []Field{added("title", a.Name)},
+			})
+
+		default:
+			changes = append(changes, compareArticles(
+				old.Articles[pair.Old],
+				new.Articles[pair.New],
+				validation.Path{validation.Article: pair.Old},
+				validation.Path{validation.Article: pair.New},
+			)...)
+		}
+	}
+
+	return changes
+}
+
+func compareArticles(old, new squad.Article, oldPath, newPath validation.Path) []Change {
+	var changes []Change
+
+	for _, pair := range MatchParagraphs(old.Paragraphs, new.Paragraphs) {
+		op := with(oldPath, validation.Paragraph, pair.Old)
+		np := with(newPath, validation.Paragraph, pair.New)
+
+		switch {
+		case pair.Removed():
+			p := old.Paragraphs[pair.Old]
+			changes = append(changes, Change{
+				Kind:    Removed,
+				Type:    validation.Paragraph,
+				OldPath: op,
+				Article: old.Name,
+				Label:   p.Context,
+				Fields:  []Field{removed("context", p.Context)},
+			})
+
+		case pair.Added():
+			p := new.Paragraphs[pair.New]
+			changes = append(changes, Change{
+				Kind:    Added,
+				Type:    validation.Paragraph,
+				NewPath: np,
+				Article: new.Name,
+				Label:   p.Context,
+				Fields:  []Field{added("context", p.Context)},
+			})
+
+		default:
+			oldPara, newPara := old.Paragraphs[pair.Old], new.Paragraphs[pair.New]
+			if oldPara.Context != newPara.Context {
+				changes = append(changes, Change{
+					Kind:    Modified,
+					Type:    validation.Paragraph,
+					OldPath: op,
+					NewPath: np,
+					Article: new.Name,
+					Label:   newPara.Context,
+					Fields:  []Field{modified("context", oldPara.Context, newPara.Context)},
+				})
+			}
+			changes = append(changes, compareParagraphs(oldPara, newPara, new.Name, op, np)...)
+		}
+	}
+
+	return changes
+}
+
+func compareParagraphs(
+	old, new squad.Paragraph,
+	article string,
+	oldPath, newPath validation.Path,
+) []Change {
+	var changes []Change
+
+	for _, pair := range MatchQAs(old.QAs, new.QAs) {
+		op := with(oldPath, validation.Question, pair.Old)
+		np := with(newPath, validation.Question, pair.New)
+
+		switch {
+		case pair.Removed():
+			qa := old.QAs[pair.Old]
+			changes = append(changes, Change{
+				Kind:    Removed,
+				Type:    validation.Question,
+				OldPath: op,
+				Article: article,
+				Label:   qa.Question,
+				Fields:  []Field{removed("question", qa.Question)},
+			})
+
+		case pair.Added():
+			qa := new.QAs[pair.New]
+			changes = append(changes, Change{
+				Kind:    Added,
+				Type:    validation.Question,
+				NewPath: np,
+				Article: article,
+				Label:   qa.Question,
+				Fields:  []Field{added("question", qa.Question)},
+			})
+
+		default:
+			newQA := new.QAs[pair.New]
+			if fields := compareQAs(old.QAs[pair.Old], newQA); len(fields) > 0 {
+				changes = append(changes, Change{
+					Kind:    Modified,
+					Type:    validation.Question,
+					OldPath: op,
+					NewPath: np,
+					Article: article,
+					Label:   newQA.Question,
+					Fields:  fields,
+				})
+			}
+		}
+	}
+
+	return changes
+}
+
+func compareQAs(old, new squad.QA) []Field {
+	var fields []Field
+
+	if old.Question != new.Question {
+		fields = append(fields, modified("question", old.Question, new.Question))
+	}
+
+	if old.Impossible != new.Impossible {
+		fields = append(fields, modified(
+			"is_impossible",
+			strconv.FormatBool(old.Impossible),
+			strconv.FormatBool(new.Impossible),
+		))
+	}
+
+	fields = append(fields, compareAnswers(old.CorrectAnswers, new.CorrectAnswers, "answer")...)
+	fields = append(fields, compareAnswers(old.PlausibleAnswers, new.PlausibleAnswers, "plausible answer")...)
+	return fields
+}
+
+func compareAnswers(old, new []squad.Answer, label string) []Field {
+	var fields []Field
+
+	for i := range max(len(old), len(new)) {
+		name := fmt.Sprintf("%s %d", label, i+1)
+
+		switch {
+		case i >= len(new):
+			fields = append(fields, removed(name, old[i].Text))
+
+		case i >= len(old):
+			fields = append(fields, added(name, new[i].Text))
+
+		default:
+			if old[i].Text != new[i].Text {
+				fields = append(fields, modified(name, old[i].Text, new[i].Text))
+			}
+			if old[i].Start != new[i].Start {
+				fields = append(fields, modified(
+					name+" start",
+					strconv.Itoa(old[i].Start),
+					strconv.Itoa(new[i].Start),
+				))
+			}
+		}
+	}
+
+	return fields
+}
+
+func added(name, value string) Field {
+	return Field{Name: name, Edits: []text.Edit{{Op: text.Insert, Text: value}}}
+}
+
+func removed(name, value string) Field {
+	return Field{Name: name, Edits: []text.Edit{{Op: text.Delete, Text: value}}}
+}
+
+func modified(name, old, new string) Field {
+	return Field{Name: name, Edits: text.Diff(old, new)}
+}
+
+func with(path validation.Path, itemType validation.ItemType, index int) validation.Path {
+	if index == -1 {
+		return nil
+	}
+
+	result := maps.Clone(path)
+	result[itemType] = index
+	return result
+}
@@ -0,0 +1,136 @@
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/donderom/sqwat/diff"
+	"github.com/donderom/sqwat/squad"
+	"github.com/donderom/sqwat/text"
+	"github.com/donderom/sqwat/validation"
+)
+
+func TestMatchParagraphs(t *testing.T) {
+	t.Parallel()
+
+	old := []squad.Paragraph{
+		{Context: "Go is a statically typed language."},
+		{Context: "Rust emphasizes performance and safety."},
+		{Context: "Removed paragraph."},
+	}
+	new := []squad.Paragraph{
+		{Context: "Rust emphasizes performance and type safety."},
+		{Context: "Go is a statically typed language."},
+		{Context: "Something completely different."},
+	}
+
+	assert.Equal(t, []diff.Pair{
+		{Old: 0, New: 1},
+		{Old: 1, New: 0},
+		{Old: 2, New: -1},
+		{Old: -1, New: 2},
+	}, diff.MatchParagraphs(old, new))
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no changes", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Empty(t, diff.Compare(data(), data()))
+	})
+
+	t.Run("articles", func(t *testing.T) {
+		t.Parallel()
+
+		new := data()
+		new.Articles[0].Name = "Rust"
+
+		changes := diff.Compare(data(), new)
+		require.Len(t, changes, 2)
+		assert.Equal(t, diff.Removed, changes[0].Kind)
+		assert.Equal(t, validation.Article, changes[0].Type)
+		assert.Equal(t, diff.Added, changes[1].Kind)
+		assert.Equal(t, "Rust", changes[1].Label)
+	})
+
+	t.Run("modified question", func(t *testing.T) {
+		t.Parallel()
+
+		new := data()
+		qa := new.At(0).At(0).At(0)
+		qa.Question = "What was Go?"
+		qa.CorrectAnswers[0].Start = 9
+
+		changes := diff.Compare(data(), new)
+		require.Len(t, changes, 1)
+
+		change := changes[0]
+		assert.Equal(t, diff.Modified, change.Kind)
+		assert.Equal(t, validation.Question, change.Type)
+		assert.Equal(t, 0, change.NewPath.To(validation.Question))
+		require.Len(t, change.Fields, 2)
+		assert.Equal(t, "question", change.Fields[0].Name)
+		assert.Contains(t, change.Fields[0].Edits, text.Edit{Op: text.Insert, Text: "was"})
+		assert.Equal(t, "answer 1 start", change.Fields[1].Name)
+	})
+
+	t.Run("added and removed questions", func(t *testing.T) {
+		t.Parallel()
+
+		new := data()
+		p := new.At(0).At(0)
+		p.Remove(0)
+		p.Add(squad.QA{Id: "3", Question: "Who made Go?"})
+
+		stats := diff.Summarize(diff.Compare(data(), new))
+		assert.Equal(t, 1, stats[diff.Added])
+		assert.Equal(t, 1, stats[diff.Removed])
+		assert.Equal(t, 0, stats[diff.Modified])
+	})
+
+	t.Run("modified context", func(t *testing.T) {
+		t.Parallel()
+
+		new := data()
+		new.At(0).At(0).Context = "Go is a compiled statically typed language."
+
+		changes := diff.Compare(data(), new)
+		require.Len(t, changes, 1)
+		assert.Equal(t, diff.Modified, changes[0].Kind)
+		assert.Equal(t, validation.Paragraph, changes[0].Type)
+	})
+}
+
+func data() *squad.SQuAD {
+	return &squad.SQuAD{
+		Version: "v2.0",
+		Articles: []squad.Article{
+			{
+				Name: "Go",
+				Paragraphs: []squad.Paragraph{
+					{
+						Context: "Go is a statically typed language.",
+						QAs: []squad.QA{
+							{
+								Id:       "1",
+								Question: "What is Go?",
+								CorrectAnswers: []squad.Answer{
+									{Text: "a statically typed language", Start: 6},
+								},
+							},
+							{
+								Id:         "2",
+								Question:   "Is Go dynamic?",
+								Impossible: true,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
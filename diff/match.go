@@ -0,0 +1,125 @@
+package diff
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/donderom/sqwat/squad"
+	"github.com/donderom/sqwat/text"
+)
+
+// Minimal context similarity for two paragraphs to be considered the same
+const Threshold = 0.5
+
+// Pair holds the indices of matched items, -1 stands for a missing one.
+type Pair struct {
+	Old int
+	New int
+}
+
+func (p Pair) Added() bool   { return p.Old == -1 }
+func (p Pair) Removed() bool { return p.New == -1 }
+
+func MatchArticles(old, new []squad.Article) []Pair {
+	return pairs(len(old), len(new), matchBy(old, new, squad.Article.Title))
+}
+
+func MatchParagraphs(old, new []squad.Paragraph) []Pair {
+	matched := matchBy(old, new, squad.Paragraph.Title)
+
+	used := make(map[int]struct{}, len(matched))
+	for _, j := range matched {
+		used[j] = struct{}{}
+	}
+
+	type candidate struct {
+		old, new   int
+		similarity float64
+	}
+
+	var candidates []candidate
+	for i := range old {
+		if _, ok := matched[i]; ok {
+			continue
+		}
+		for j := range new {
+			if _, ok := used[j]; ok {
+				continue
+			}
+			s := text.Similarity(old[i].Context, new[j].Context)
+			if s >= Threshold {
+				candidates = append(candidates, candidate{i, j, s})
+			}
+		}
+	}
+
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		return cmp.Compare(b.similarity, a.similarity)
+	})
+
+	for _, c := range candidates {
+		if _, ok := matched[c.old]; ok {
+			continue
+		}
+		if _, ok := used[c.new]; ok {
+			continue
+		}
+		matched[c.old] = c.new
+		used[c.new] = struct{}{}
+	}
+
+	return pairs(len(old), len(new), matched)
+}
+
+func MatchQAs(old, new []squad.QA) []Pair {
+	return pairs(len(old), len(new), matchBy(old, new, qaKey))
+}
+
+func qaKey(qa squad.QA) string {
+	if qa.IsEmptyID() {
+		return "question:" + qa.Question
+	}
+	return qa.Id
+}
+
+// matchBy pairs items with equal keys in order of appearance.
+func matchBy[T any](old, new []T, key func(T) string) map[int]int {
+	queues := make(map[string][]int, len(new))
+	for j, item := range new {
+		k := key(item)
+		queues[k] = append(queues[k], j)
+	}
+
+	matched := make(map[int]int, len(old))
+	for i, item := range old {
+		k := key(item)
+		if queue := queues[k]; len(queue) > 0 {
+			matched[i] = queue[0]
+			queues[k] = queue[1:]
+		}
+	}
+
+	return matched
+}
+
+func pairs(numOld, numNew int, matched map[int]int) []Pair {
+	result := make([]Pair, 0, max(numOld, numNew))
+	used := make(map[int]struct{}, len(matched))
+
+	for i := range numOld {
+		if j, ok := matched[i]; ok {
+			result = append(result, Pair{Old: i, New: j})
+			used[j] = struct{}{}
+		} else {
+			result = append(result, Pair{Old: i, New: -1})
+		}
+	}
+
+	for j := range numNew {
+		if _, ok := used[j]; !ok {
+			result = append(result, Pair{Old: -1, New: j})
+		}
+	}
+
+	return result
+}
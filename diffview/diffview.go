@@ -0,0 +1,167 @@
+package diffview
+
+import (
+	"slices"
+
+	"github.com/donderom/sqwat/diff"
+	"github.com/donderom/sqwat/keyset"
+	"github.com/donderom/sqwat/style"
+	"github.com/donderom/sqwat/teax"
+	"github.com/donderom/sqwat/text"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/donderom/bubblon"
+)
+
+type Item = diff.Change
+
+type Diff struct {
+	list       teax.ViewList[Item, text.Segment]
+	changes    []Item
+	standalone bool
+	// Saves the changes once they are reviewed
+	save tea.Cmd
+}
+
+var _ tea.Model = Diff{}
+
+var (
+	keys []key.Binding = []key.Binding{
+		keyset.Esc,
+	}
+
+	fullKeys []key.Binding = []key.Binding{
+		keyset.Next,
+		keyset.Prev,
+	}
+
+	delegate teax.Delegate[Item] = teax.Delegate[Item]{
+		Style:           ChangeStyle,
+		ItemName:        "change",
+		ShowDescription: true,
+		ShortHelpKeys:   keys,
+		FullHelpKeys:    slices.Concat(keys, fullKeys),
+	}
+
+	pendingKeys []key.Binding = []key.Binding{
+		keyset.Save,
+		keyset.Esc,
+	}
+
+	pendingDelegate teax.Delegate[Item] = teax.Delegate[Item]{
+		Style:           ChangeStyle,
+		ItemName:        "change",
+		ShowDescription: true,
+		ShortHelpKeys:   pendingKeys,
+		FullHelpKeys:    slices.Concat(pendingKeys, fullKeys),
+	}
+
+	ChangeStyle teax.StyleFunc[Item] = teax.StyleFunc[Item](
+		func(defaultStyles teax.Styles) teax.ItemStyles[Item] {
+			return func(item Item) teax.Styles {
+				styles := defaultStyles
+				switch item.Kind {
+				case diff.Added:
+					border := style.Border.Multi
+					styles.NormalDesc = border.Apply(styles.NormalDesc)
+					styles.SelectedDesc = border.Apply(styles.SelectedDesc)
+				case diff.Removed:
+					border := style.Border.Error
+					styles.NormalDesc = border.Apply(styles.NormalDesc)
+					styles.SelectedDesc = border.Apply(styles.SelectedDesc)
+				}
+				return styles
+			}
+		})
+)
+
+func New(title string, changes []Item) Diff {
+	list := teax.NewViewList[text.Segment](changes, title, delegate)
+	return Diff{
+		list:    list,
+		changes: changes,
+	}
+}
+
+// NewPending reviews the changes not saved yet, save is run on ctrl+s.
+// It reports an error with teax.Saved and closes the screen otherwise.
+func NewPending(title string, changes []Item, save tea.Cmd) Diff {
+	list := teax.NewViewList[text.Segment](changes, title, pendingDelegate)
+	return Diff{
+		list:    list,
+		changes: changes,
+		save:    save,
+	}
+}
+
+// Standalone makes the screen quit the app on esc as there is
+// nothing to return to.
+func (m Diff) Standalone() Diff {
+	m.standalone = true
+	return m
+}
+
+func (m Diff) Init() tea.Cmd {
+	return nil
+}
+
+func (m Diff) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.Resize(msg)
+		m.updateContent()
+		return m, nil
+
+	case teax.Saved:
+		if msg.Err != nil {
+			m.list.StopSpinner()
+			return m, m.list.NewStatus(style.Error.Render(msg.Err.Error()))
+		}
+
+	case tea.KeyMsg:
+		if m.list.Unfiltered() {
+			switch {
+			case key.Matches(msg, keyset.Save) && m.save != nil:
+				return m, tea.Batch(m.list.StartSpinner(), m.save)
+
+			case key.Matches(msg, keyset.Esc):
+				if m.standalone {
+					return m, tea.Quit
+				}
+				return m, bubblon.Close
+
+			case key.Matches(msg, keyset.Quit):
+				return m, tea.Quit
+			}
+		}
+	}
+
+	m.list, cmd = m.list.Update(msg)
+	m.updateContent()
+	return m, cmd
+}
+
+func (m Diff) View() string {
+	helpView := m.list.Help.View(m.list)
+	m.list.DecreaseHeight(lipgloss.Height(helpView))
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		style.Top.Render(m.list.View()),
+		m.list.Viewport.View(),
+		style.Bot.Render(helpView),
+	)
+}
+
+func (m *Diff) updateContent() {
+	if m.list.ItemSelected() {
+		m.list.Viewport.SetContent(m.changes[m.list.GlobalIndex()].Render())
+	} else {
+		m.list.Viewport.Blur()
+		m.list.Viewport.SetContent("")
+	}
+}
@@ -0,0 +1,229 @@
+package diskview
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/donderom/sqwat/app"
+	"github.com/donderom/sqwat/diff"
+	"github.com/donderom/sqwat/diffview"
+	"github.com/donderom/sqwat/keyset"
+	"github.com/donderom/sqwat/merge"
+	"github.com/donderom/sqwat/mergeview"
+	"github.com/donderom/sqwat/squad"
+	"github.com/donderom/sqwat/style"
+	"github.com/donderom/sqwat/teax"
+	"github.com/donderom/sqwat/text"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/donderom/bubblon"
+)
+
+type Item = diff.Change
+
+// File is the open file someone else has changed.
+type File interface {
+	// Changes made on disk since the file was last read or written
+	Changes() ([]diff.Change, error)
+	// Reload replaces the data in memory with the file
+	Reload() error
+	// Overwrite saves the data in memory over the changes on disk
+	Overwrite() error
+	// Merge saves the changes on disk merged with the ones in memory.
+	// Without resolutions it's only done when there are no conflicts.
+	Merge(resolutions merge.Resolutions) (merge.Result, error)
+}
+
+type loaded struct {
+	changes []Item
+	err     error
+}
+
+type resolved struct {
+	conflicts []merge.Conflict
+	err       error
+}
+
+type Disk struct {
+	list     teax.ViewList[Item, text.Segment]
+	changes  []Item
+	data     *squad.SQuAD
+	filename string
+	dataset  teax.Dataset
+	file     File
+	inSync   bool
+}
+
+var _ tea.Model = Disk{}
+
+var (
+	keys []key.Binding = []key.Binding{
+		keyset.Merge,
+		keyset.Reload,
+		keyset.Overwrite,
+		key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "decide later")),
+	}
+
+	fullKeys []key.Binding = []key.Binding{
+		keyset.Next,
+		keyset.Prev,
+	}
+
+	delegate teax.Delegate[Item] = teax.Delegate[Item]{
+		Style:           diffview.ChangeStyle,
+		ItemName:        "change",
+		ShowDescription: true,
+		ShortHelpKeys:   keys,
+		FullHelpKeys:    slices.Concat(keys, fullKeys),
+	}
+)
+
+// New offers to reload, overwrite or merge the file changed on disk
+// while the changes in memory are not saved yet.
+func New(data *squad.SQuAD, filename string, dataset teax.Dataset, file File) Disk {
+	title := filename + " changed on disk"
+	return Disk{
+		list:     teax.NewViewList[text.Segment]([]Item{}, title, delegate),
+		data:     data,
+		filename: filename,
+		dataset:  dataset,
+		file:     file,
+		inSync:   true,
+	}
+}
+
+func (m Disk) Init() tea.Cmd {
+	return tea.Batch(m.list.StartSpinner(), m.load)
+}
+
+func (m Disk) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.Resize(msg)
+		m.updateContent()
+		return m, nil
+
+	case loaded:
+		m.inSync = false
+		m.list.StopSpinner()
+		if msg.err != nil {
+			return m, m.list.NewStatus(style.Error.Render(msg.err.Error()))
+		}
+
+		m.changes = msg.changes
+		items := make([]list.Item, len(msg.changes))
+		for i, c := range msg.changes {
+			items[i] = c
+		}
+		cmd = m.list.SetItems(items)
+		m.updateContent()
+		return m, tea.Batch(cmd, m.list.NewStatus(diff.Summarize(msg.changes).String()))
+
+	case resolved:
+		m.inSync = false
+		m.list.StopSpinner()
+		if msg.err != nil && !teax.IsWarning(msg.err) {
+			return m, m.list.NewStatus(style.Error.Render(msg.err.Error()))
+		}
+
+		if len(msg.conflicts) > 0 {
+			title := fmt.Sprintf("Merge %s with the changes on disk", m.filename)
+			return m, bubblon.Open(mergeview.New(title, msg.conflicts, m.done))
+		}
+
+		return m, m.restart(msg.err)
+
+	case tea.KeyMsg:
+		if m.inSync {
+			return m, nil
+		}
+
+		if m.list.Unfiltered() {
+			switch {
+			case key.Matches(msg, keyset.Esc):
+				return m, bubblon.Close
+
+			case key.Matches(msg, keyset.Quit):
+				return m, tea.Quit
+
+			case key.Matches(msg, keyset.Reload):
+				return m.resolve(func() resolved { return resolved{err: m.file.Reload()} })
+
+			case key.Matches(msg, keyset.Overwrite):
+				return m.resolve(func() resolved { return resolved{err: m.file.Overwrite()} })
+
+			case key.Matches(msg, keyset.Merge):
+				return m.resolve(func() resolved {
+					result, err := m.file.Merge(nil)
+					return resolved{conflicts: result.Conflicts, err: err}
+				})
+			}
+		}
+	}
+
+	m.list, cmd = m.list.Update(msg)
+	m.updateContent()
+	return m, cmd
+}
+
+func (m Disk) View() string {
+	helpView := m.list.Help.View(m.list)
+	m.list.DecreaseHeight(lipgloss.Height(helpView))
+
+	listView := m.list.View()
+	if m.inSync {
+		listView = style.Faint.Render(listView)
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		style.Top.Render(listView),
+		m.list.Viewport.View(),
+		style.Bot.Render(helpView),
+	)
+}
+
+func (m Disk) load() tea.Msg {
+	changes, err := m.file.Changes()
+	return loaded{changes: changes, err: err}
+}
+
+func (m Disk) resolve(f func() resolved) (tea.Model, tea.Cmd) {
+	m.inSync = true
+	return m, tea.Batch(m.list.StartSpinner(), func() tea.Msg { return f() })
+}
+
+// done saves the merge with the conflicts resolved.
+func (m Disk) done(resolutions merge.Resolutions) tea.Cmd {
+	return func() tea.Msg {
+		_, err := m.file.Merge(resolutions)
+		if err != nil && !teax.IsWarning(err) {
+			return mergeview.Resolved{Err: err}
+		}
+		return m.restart(err)()
+	}
+}
+
+// restart opens the dataset again once the file is resolved, with the
+// warning of the save if any.
+func (m Disk) restart(warning error) tea.Cmd {
+	cmd := bubblon.ReplaceAll(app.New(m.data, m.filename, m.dataset))
+	if warning != nil {
+		return tea.Sequence(cmd, bubblon.Cmd(teax.Saved{Err: warning}))
+	}
+	return cmd
+}
+
+func (m *Disk) updateContent() {
+	if m.list.ItemSelected() {
+		m.list.Viewport.SetContent(m.changes[m.list.GlobalIndex()].Render())
+	} else {
+		m.list.Viewport.Blur()
+		m.list.Viewport.SetContent("")
+	}
+}
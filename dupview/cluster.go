@@ -0,0 +1,213 @@
+package dupview
+
+import (
+	"slices"
+
+	"github.com/donderom/sqwat/dedup"
+	"github.com/donderom/sqwat/keyset"
+	"github.com/donderom/sqwat/squad"
+	"github.com/donderom/sqwat/style"
+	"github.com/donderom/sqwat/teax"
+	"github.com/donderom/sqwat/validation"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/donderom/bubblon"
+)
+
+type Member = dedup.Member
+
+type kept struct{}
+
+type cluster struct {
+	list    teax.ViewList[Member, squad.Answer]
+	cluster dedup.Cluster
+	data    *squad.SQuAD
+	dataset teax.Dataset
+	changed *bool
+	mode    teax.Mode
+}
+
+var _ tea.Model = cluster{}
+
+var (
+	keep key.Binding = keyset.NewEnter("keep, delete others")
+
+	memberKeys []key.Binding = []key.Binding{
+		keep,
+		keyset.Delete,
+		keyset.Esc,
+	}
+
+	memberDelegate teax.Delegate[Member] = teax.Delegate[Member]{
+		Style:           teax.IdentityStyles[Member](),
+		ItemName:        "question",
+		ShowDescription: true,
+		ShortHelpKeys:   memberKeys,
+		FullHelpKeys:    slices.Concat(memberKeys, fullKeys),
+	}
+)
+
+func newCluster(
+	c dedup.Cluster,
+	data *squad.SQuAD,
+	dataset teax.Dataset,
+	changed *bool,
+) cluster {
+	list := teax.NewViewList[squad.Answer](c.Members, c.Description(), memberDelegate)
+	list.List = teax.Guard(list.List, dataset, keep)
+
+	return cluster{
+		list:    list,
+		cluster: c,
+		data:    data,
+		dataset: dataset,
+		changed: changed,
+	}
+}
+
+func (m cluster) Init() tea.Cmd {
+	return nil
+}
+
+func (m cluster) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.Resize(msg)
+		m.updateContent()
+		return m, nil
+
+	case kept:
+		return m.resolve(dedup.Keep(m.data, m.cluster, m.list.GlobalIndex()))
+
+	case teax.Deleted:
+		member := m.cluster.Members[m.list.GlobalIndex()]
+		return m.resolve(dedup.Remove(m.data, []Member{member}))
+
+	case tea.KeyMsg:
+		if m.list.ReadOnly() && key.Matches(msg, keyset.View, keyset.Delete) {
+			return m, nil
+		}
+
+		if key.Matches(msg, keyset.Esc) && m.mode != nil {
+			m.mode = nil
+			return m, nil
+		}
+	}
+
+	if m.mode != nil {
+		m.mode, cmd = m.mode.Update(msg)
+		return m, cmd
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.list.Unfiltered() {
+			switch {
+			case key.Matches(msg, keyset.Esc):
+				return m, bubblon.Close
+
+			case key.Matches(msg, keyset.Quit):
+				return m, tea.Quit
+
+			case key.Matches(msg, keyset.View):
+				if m.list.ItemSelected() {
+					m.mode = teax.Confirmation[kept]("Keep this question and delete the others?")
+				}
+				return m, nil
+
+			case key.Matches(msg, keyset.Delete):
+				if m.list.ItemSelected() {
+					m.mode = teax.Confirmation[teax.Deleted]("Delete this question?")
+				}
+				return m, nil
+			}
+		}
+	}
+
+	m.list, cmd = m.list.Update(msg)
+	m.updateContent()
+	return m, cmd
+}
+
+func (m cluster) View() string {
+	helpView := m.list.Help.View(m.list)
+	if m.mode != nil {
+		helpView = m.list.Help.View(m.mode.KeyMap())
+		m.list.DecreaseHeight(m.mode.Height())
+	}
+	m.list.DecreaseHeight(lipgloss.Height(helpView))
+
+	listView := m.list.View()
+	if m.mode != nil {
+		listView = style.Faint.Render(listView)
+	}
+
+	sections := []string{style.Top.Render(listView)}
+	if m.mode != nil {
+		sections = append(sections, m.mode.View())
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		append(sections, m.list.Viewport.View(), style.Bot.Render(helpView))...,
+	)
+}
+
+// resolve journals, audits and schedules the removals to be saved, the
+// questions are put back when they can't be journaled.
+func (m cluster) resolve(removals []dedup.Removal) (tea.Model, tea.Cmd) {
+	m.mode = nil
+
+	edits := make([]teax.Edit, len(removals))
+	for i, r := range removals {
+		edits[i] = teax.Edit{
+			Change: teax.Deletion,
+			Coll:   m.paragraph(r),
+			Index:  r.Path.To(validation.Question),
+			Before: r.QA,
+		}
+	}
+
+	var err error
+	if journaler, ok := m.dataset.(teax.Journaler); ok {
+		err = journaler.Journal(edits...)
+		if err != nil && !teax.IsWarning(err) {
+			dedup.Restore(m.data, removals)
+			return m, m.list.NewStatus(style.Error.Render(err.Error()))
+		}
+	}
+
+	*m.changed = true
+	cmd := bubblon.Close
+	if err != nil {
+		cmd = tea.Sequence(bubblon.Close, bubblon.Cmd(teax.Error{Err: err}))
+	}
+	if saver, ok := m.dataset.(teax.Saver); ok {
+		cmd = tea.Batch(saver.Schedule(func() {
+			dedup.Restore(m.data, removals)
+		}), cmd)
+	}
+	return m, cmd
+}
+
+func (m cluster) paragraph(r dedup.Removal) *squad.Paragraph {
+	return m.data.At(r.Path.To(validation.Article)).At(r.Path.To(validation.Paragraph))
+}
+
+func (m *cluster) updateContent() {
+	if !m.list.ItemSelected() {
+		m.list.Viewport.SetContent("")
+		return
+	}
+
+	member := m.cluster.Members[m.list.GlobalIndex()]
+	m.list.Viewport.Highlight(
+		[]rune(member.Context),
+		member.QA.Answers(),
+		member.QA.Highlight(),
+	)
+}
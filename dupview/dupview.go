@@ -0,0 +1,248 @@
+package dupview
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/donderom/sqwat/app"
+	"github.com/donderom/sqwat/dedup"
+	"github.com/donderom/sqwat/keyset"
+	"github.com/donderom/sqwat/squad"
+	"github.com/donderom/sqwat/style"
+	"github.com/donderom/sqwat/teax"
+	"github.com/donderom/sqwat/text"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/donderom/bubblon"
+)
+
+type Item = dedup.Cluster
+
+type detected struct {
+	clusters []Item
+	intended dedup.Intended
+	err      error
+}
+
+type marked struct {
+	index int
+	err   error
+}
+
+type Dups struct {
+	list     teax.ViewList[Item, text.Segment]
+	clusters []Item
+	intended dedup.Intended
+	data     *squad.SQuAD
+	filename string
+	// Marks are kept for the session only without a sidecar
+	sidecar string
+	dataset teax.Dataset
+	// Set once questions are deleted, screens below are stale then
+	changed *bool
+	inSync  bool
+}
+
+var _ tea.Model = Dups{}
+
+var (
+	keys []key.Binding = []key.Binding{
+		keyset.NewEnter("resolve"),
+		keyset.Intended,
+		keyset.Esc,
+	}
+
+	fullKeys []key.Binding = []key.Binding{
+		keyset.Next,
+		keyset.Prev,
+	}
+
+	delegate teax.Delegate[Item] = teax.Delegate[Item]{
+		Style:           teax.IdentityStyles[Item](),
+		ItemName:        "cluster",
+		ShowDescription: true,
+		ShortHelpKeys:   keys,
+		FullHelpKeys:    slices.Concat(keys, fullKeys),
+	}
+)
+
+func New(data *squad.SQuAD, filename string, dataset teax.Dataset) Dups {
+	list := teax.NewViewList[text.Segment]([]Item{}, "Near duplicates", delegate)
+	list.List = teax.Guard(list.List, dataset)
+
+	return Dups{
+		list:     list,
+		data:     data,
+		filename: filename,
+		sidecar:  dedup.SidecarPath(filename),
+		dataset:  dataset,
+		changed:  new(bool),
+		inSync:   true,
+	}
+}
+
+// InMemory keeps the marks for the session only as the data is not
+// from a file to keep the sidecar next to.
+func (m Dups) InMemory() Dups {
+	m.sidecar = ""
+	return m
+}
+
+func (m Dups) Init() tea.Cmd {
+	return tea.Batch(m.list.StartSpinner(), m.detect)
+}
+
+func (m Dups) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.Resize(msg)
+		m.updateContent()
+		return m, nil
+
+	case detected:
+		m.inSync = false
+		m.list.StopSpinner()
+		if msg.err != nil {
+			return m, m.list.NewStatus(style.Error.Render(msg.err.Error()))
+		}
+
+		m.clusters = msg.clusters
+		m.intended = msg.intended
+		items := make([]list.Item, len(msg.clusters))
+		for i, c := range msg.clusters {
+			items[i] = c
+		}
+		cmd = m.list.SetItems(items)
+		m.updateContent()
+		return m, cmd
+
+	case marked:
+		m.inSync = false
+		m.list.StopSpinner()
+		if msg.err != nil {
+			m.intended = m.intended[:len(m.intended)-1]
+			return m, m.list.NewStatus(style.Error.Render(msg.err.Error()))
+		}
+
+		m.clusters = slices.Delete(m.clusters, msg.index, msg.index+1)
+		cmd = m.list.Remove(msg.index)
+		m.updateContent()
+		return m, cmd
+
+	case teax.Error:
+		return m, m.list.NewStatus(style.Error.Render(msg.Err.Error()))
+
+	case teax.Saved:
+		if saver, ok := m.dataset.(teax.Saver); ok && errors.Is(msg.Err, teax.ErrModified) {
+			return m, bubblon.Open(saver.Modified())
+		}
+		if msg.Err != nil {
+			return m, m.list.NewStatus(style.Error.Render(msg.Err.Error()))
+		}
+		return m, nil
+
+	case bubblon.Closed:
+		m.inSync = true
+		return m, tea.Batch(m.list.StartSpinner(), m.detect)
+
+	case tea.KeyMsg:
+		if m.inSync {
+			return m, nil
+		}
+
+		if m.list.Unfiltered() {
+			switch {
+			case key.Matches(msg, keyset.Esc):
+				if *m.changed {
+					return m, bubblon.ReplaceAll(app.New(m.data, m.filename, m.dataset))
+				}
+				return m, bubblon.Close
+
+			case key.Matches(msg, keyset.Quit):
+				return m, tea.Quit
+
+			case key.Matches(msg, keyset.View):
+				if m.list.ItemSelected() {
+					cluster := m.clusters[m.list.GlobalIndex()]
+					return m, bubblon.Open(newCluster(cluster, m.data, m.dataset, m.changed))
+				}
+
+			case key.Matches(msg, keyset.Intended) && !m.list.ReadOnly():
+				if m.list.ItemSelected() {
+					return m.mark(m.list.GlobalIndex())
+				}
+			}
+		}
+	}
+
+	m.list, cmd = m.list.Update(msg)
+	m.updateContent()
+	return m, cmd
+}
+
+func (m Dups) View() string {
+	helpView := m.list.Help.View(m.list)
+	m.list.DecreaseHeight(lipgloss.Height(helpView))
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		style.Top.Render(m.list.View()),
+		m.list.Viewport.View(),
+		style.Bot.Render(helpView),
+	)
+}
+
+func (m Dups) detect() tea.Msg {
+	intended := m.intended
+	if m.sidecar != "" {
+		var err error
+		if intended, err = dedup.LoadIntended(m.sidecar); err != nil {
+			return detected{err: err}
+		}
+	}
+
+	clusters := dedup.Detect(m.data, dedup.DefaultOptions)
+	return detected{clusters: intended.Filter(clusters), intended: intended}
+}
+
+func (m Dups) mark(index int) (tea.Model, tea.Cmd) {
+	m.intended = m.intended.Mark(m.clusters[index])
+	m.inSync = true
+
+	intended, path := m.intended, m.sidecar
+	return m, tea.Batch(
+		m.list.StartSpinner(),
+		func() tea.Msg {
+			if path == "" {
+				return marked{index: index}
+			}
+			return marked{index: index, err: intended.Save(path)}
+		},
+	)
+}
+
+func (m *Dups) updateContent() {
+	if !m.list.ItemSelected() {
+		m.list.Viewport.SetContent("")
+		return
+	}
+
+	cluster := m.clusters[m.list.GlobalIndex()]
+	lines := make([]string, len(cluster.Members))
+	for i, member := range cluster.Members {
+		lines[i] = fmt.Sprintf("%s %s %s",
+			style.Highlight.Render(fmt.Sprintf("%d.", i+1)),
+			member.QA.Question,
+			style.Faint.Render(member.Description()),
+		)
+	}
+
+	m.list.Viewport.SetContent(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,107 @@
+package export
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/donderom/sqwat/squad"
+)
+
+type beirDoc struct {
+	Id    string `json:"_id"`
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+type beirQuery struct {
+	Id   string `json:"_id"`
+	Text string `json:"text"`
+}
+
+type BEIRStats struct {
+	Documents int
+	Queries   int
+	// Impossible questions have no relevant document and are left out
+	Impossible int
+	// Questions with an ID seen before are left out
+	Duplicates int
+}
+
+// BEIR writes corpus.jsonl, queries.jsonl and qrels/test.tsv to dir
+// linking every answerable question to its paragraph.
+func BEIR(dir string, s *squad.SQuAD, scheme IDScheme) (stats BEIRStats, err error) {
+	corpus, err := create(filepath.Join(dir, "corpus.jsonl"))
+	if err != nil {
+		return stats, err
+	}
+	defer func() {
+		if cerr := corpus.close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	queries, err := create(filepath.Join(dir, "queries.jsonl"))
+	if err != nil {
+		return stats, err
+	}
+	defer func() {
+		if cerr := queries.close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	qrels, err := create(filepath.Join(dir, "qrels", "test.tsv"))
+	if err != nil {
+		return stats, err
+	}
+	defer func() {
+		if cerr := qrels.close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	if _, err = fmt.Fprintln(qrels.w, "query-id\tcorpus-id\tscore"); err != nil {
+		return stats, err
+	}
+
+	docs := make(map[string]struct{})
+	ids := make(map[string]struct{})
+
+	for i, a := range s.Articles {
+		for j, p := range a.Paragraphs {
+			id := ParagraphID(scheme, i, j, p.Context)
+
+			if _, ok := docs[id]; !ok {
+				docs[id] = struct{}{}
+				stats.Documents++
+				if err = corpus.write(beirDoc{Id: id, Title: a.Name, Text: p.Context}); err != nil {
+					return stats, err
+				}
+			}
+
+			for _, qa := range p.QAs {
+				if qa.Impossible {
+					stats.Impossible++
+					continue
+				}
+
+				if _, ok := ids[qa.Id]; ok {
+					stats.Duplicates++
+					continue
+				}
+				ids[qa.Id] = struct{}{}
+				stats.Queries++
+
+				if err = queries.write(beirQuery{Id: qa.Id, Text: qa.Question}); err != nil {
+					return stats, err
+				}
+
+				if _, err = fmt.Fprintf(qrels.w, "%s\t%s\t1\n", qa.Id, id); err != nil {
+					return stats, err
+				}
+			}
+		}
+	}
+
+	return stats, nil
+}
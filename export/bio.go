@@ -0,0 +1,136 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/donderom/sqwat/squad"
+	"github.com/donderom/sqwat/text"
+)
+
+type BIOLayout uint8
+
+const (
+	// Tab separated token and label per line, blank line between examples
+	CoNLL BIOLayout = iota
+	// One JSON object with tokens and labels per example
+	BIOJSONL
+)
+
+const (
+	labelOutside = "O"
+	labelBegin   = "B-ANS"
+	labelInside  = "I-ANS"
+)
+
+type bioExample struct {
+	Id       string   `json:"id"`
+	Question string   `json:"question"`
+	Tokens   []string `json:"tokens"`
+	Labels   []string `json:"labels"`
+}
+
+// BIO writes an example per question with the paragraph tokens labeled
+// by the correct answers, impossible questions are all outside. Answers
+// not on token boundaries are unmapped, questions left with no answer
+// are not written.
+func BIO(w io.Writer, s *squad.SQuAD, layout BIOLayout) error {
+	bw := bufio.NewWriter(w)
+	var unmapped squad.Unmapped
+
+	for _, a := range s.Articles {
+		for _, p := range a.Paragraphs {
+			tokens := text.Tokenize(p.Context)
+			words := make([]string, len(tokens))
+			for i, token := range tokens {
+				words[i] = token.Text
+			}
+
+			for _, qa := range p.QAs {
+				labels := make([]string, len(tokens))
+				for i := range labels {
+					labels[i] = labelOutside
+				}
+
+				aligned := 0
+				if !qa.Impossible {
+					for _, answer := range qa.CorrectAnswers {
+						from, to, ok := align(tokens, answer.From(), answer.To())
+						if !ok {
+							unmapped = append(unmapped, squad.Skipped{
+								Record: qa.Id,
+								Reason: fmt.Sprintf("answer %q not on token boundaries", answer.Text),
+							})
+							continue
+						}
+
+						aligned++
+						for i := from; i < to; i++ {
+							if labels[i] != labelOutside {
+								continue
+							}
+							if i == from {
+								labels[i] = labelBegin
+							} else {
+								labels[i] = labelInside
+							}
+						}
+					}
+
+					if aligned == 0 {
+						continue
+					}
+				}
+
+				example := bioExample{Id: qa.Id, Question: qa.Question, Tokens: words, Labels: labels}
+				if err := writeBIO(bw, example, layout); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+
+	return unmapped.Err()
+}
+
+// align finds the tokens [from, to) covering exactly the rune span.
+func align(tokens []text.Token, start, end int) (from, to int, ok bool) {
+	from = -1
+	for i, token := range tokens {
+		if token.Start == start {
+			from = i
+		}
+		if from != -1 && token.End == end {
+			return from, i + 1, true
+		}
+		if token.Start >= end {
+			break
+		}
+	}
+	return 0, 0, false
+}
+
+func writeBIO(bw *bufio.Writer, example bioExample, layout BIOLayout) error {
+	if layout == BIOJSONL {
+		return writeLine(bw, example)
+	}
+
+	question := strings.Join(strings.Fields(example.Question), " ")
+	if _, err := fmt.Fprintf(bw, "# id = %s\n# question = %s\n", example.Id, question); err != nil {
+		return err
+	}
+
+	for i, token := range example.Tokens {
+		if _, err := fmt.Fprintf(bw, "%s\t%s\n", token, example.Labels[i]); err != nil {
+			return err
+		}
+	}
+
+	return bw.WriteByte('\n')
+}
@@ -0,0 +1,100 @@
+package export
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/donderom/sqwat/text"
+)
+
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+type posting struct {
+	doc int
+	tf  int
+}
+
+// BM25 is an in-memory Okapi BM25 index over lowercased words.
+type BM25 struct {
+	postings map[string][]posting
+	lengths  []int
+	avgdl    float64
+}
+
+type Hit struct {
+	Doc   int
+	Score float64
+}
+
+func NewBM25(docs []string) *BM25 {
+	index := &BM25{
+		postings: make(map[string][]posting),
+		lengths:  make([]int, len(docs)),
+	}
+
+	total := 0
+	for i, doc := range docs {
+		words := text.Words(doc)
+		index.lengths[i] = len(words)
+		total += len(words)
+
+		tf := make(map[string]int)
+		for _, w := range words {
+			tf[w]++
+		}
+		for w, n := range tf {
+			index.postings[w] = append(index.postings[w], posting{doc: i, tf: n})
+		}
+	}
+
+	if len(docs) > 0 {
+		index.avgdl = float64(total) / float64(len(docs))
+	}
+
+	return index
+}
+
+// Search returns up to k best scoring documents for the query
+// leaving out the ones skip reports.
+func (index *BM25) Search(query string, k int, skip func(doc int) bool) []Hit {
+	n := float64(len(index.lengths))
+	scores := make(map[int]float64)
+
+	seen := make(map[string]struct{})
+	for _, w := range text.Words(query) {
+		if _, ok := seen[w]; ok {
+			continue
+		}
+		seen[w] = struct{}{}
+
+		postings := index.postings[w]
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+
+		for _, p := range postings {
+			tf := float64(p.tf)
+			norm := 1 - bm25B + bm25B*float64(index.lengths[p.doc])/index.avgdl
+			scores[p.doc] += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for doc, score := range scores {
+		if skip == nil || !skip(doc) {
+			hits = append(hits, Hit{Doc: doc, Score: score})
+		}
+	}
+
+	slices.SortFunc(hits, func(a, b Hit) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Doc, b.Doc)
+	})
+
+	return hits[:min(k, len(hits))]
+}
@@ -0,0 +1,134 @@
+package export
+
+import (
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"io"
+	"strings"
+
+	"github.com/donderom/sqwat/squad"
+)
+
+type dprCtx struct {
+	Title     string  `json:"title"`
+	Text      string  `json:"text"`
+	Score     float64 `json:"score"`
+	PassageId string  `json:"passage_id"`
+}
+
+type dprEntry struct {
+	Question         string   `json:"question"`
+	Answers          []string `json:"answers"`
+	PositiveCtxs     []dprCtx `json:"positive_ctxs"`
+	NegativeCtxs     []dprCtx `json:"negative_ctxs"`
+	HardNegativeCtxs []dprCtx `json:"hard_negative_ctxs"`
+}
+
+type DPRStats struct {
+	Questions int
+	// Impossible questions have no positive paragraph and are left out
+	Impossible int
+	// Questions with fewer hard negatives than asked for
+	Short int
+}
+
+type passage struct {
+	id      string
+	title   string
+	context string
+	lower   string
+}
+
+// DPR writes a DPR training entry per answerable question with its
+// paragraph as the positive context. Hard negatives are the k best BM25
+// matches among the other paragraphs that do not contain any answer.
+func DPR(w io.Writer, s *squad.SQuAD, k int, scheme IDScheme) (DPRStats, error) {
+	var stats DPRStats
+	var passages []passage
+	var contexts []string
+
+	for i, a := range s.Articles {
+		for j, p := range a.Paragraphs {
+			passages = append(passages, passage{
+				id:      ParagraphID(scheme, i, j, p.Context),
+				title:   a.Name,
+				context: p.Context,
+				lower:   strings.ToLower(p.Context),
+			})
+			contexts = append(contexts, p.Context)
+		}
+	}
+
+	index := NewBM25(contexts)
+	gold := 0
+
+	// Entries are written as they are made, large datasets don't fit
+	// in memory twice
+	enc := jsontext.NewEncoder(w, jsontext.WithIndent("  "))
+	if err := enc.WriteToken(jsontext.BeginArray); err != nil {
+		return stats, err
+	}
+
+	for _, a := range s.Articles {
+		for _, p := range a.Paragraphs {
+			for _, qa := range p.QAs {
+				if qa.Impossible || len(qa.CorrectAnswers) == 0 {
+					stats.Impossible++
+					continue
+				}
+				stats.Questions++
+
+				answers := make([]string, 0, len(qa.CorrectAnswers))
+				for _, answer := range qa.CorrectAnswers {
+					answers = append(answers, answer.Text)
+				}
+
+				positive := passages[gold]
+				entry := dprEntry{
+					Question:     qa.Question,
+					Answers:      answers,
+					PositiveCtxs: []dprCtx{{Title: positive.title, Text: positive.context, PassageId: positive.id}},
+					NegativeCtxs: []dprCtx{},
+				}
+
+				hits := index.Search(qa.Question, k, func(doc int) bool {
+					return doc == gold || containsAny(passages[doc].lower, answers)
+				})
+				if len(hits) < k {
+					stats.Short++
+				}
+
+				entry.HardNegativeCtxs = make([]dprCtx, 0, len(hits))
+				for _, hit := range hits {
+					negative := passages[hit.Doc]
+					entry.HardNegativeCtxs = append(entry.HardNegativeCtxs, dprCtx{
+						Title:     negative.title,
+						Text:      negative.context,
+						Score:     hit.Score,
+						PassageId: negative.id,
+					})
+				}
+
+				if err := json.MarshalEncode(enc, entry); err != nil {
+					return stats, err
+				}
+			}
+			gold++
+		}
+	}
+
+	return stats, enc.WriteToken(jsontext.EndArray)
+}
+
+func containsAny(context string, answers []string) bool {
+	for _, answer := range answers {
+		// Every context contains an empty answer
+		if answer == "" {
+			continue
+		}
+		if strings.Contains(context, strings.ToLower(answer)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package export
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json/v2"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type IDScheme uint8
+
+const (
+	// Paragraph IDs from article and paragraph indices, e.g. a0p3
+	IndexIDs IDScheme = iota
+	// Paragraph IDs from the context hash, identical contexts share one
+	HashIDs
+)
+
+// ParagraphID is a stable ID of the paragraph j of the article i.
+func ParagraphID(scheme IDScheme, i, j int, context string) string {
+	if scheme == HashIDs {
+		sum := sha1.Sum([]byte(context))
+		return "c" + hex.EncodeToString(sum[:8])
+	}
+	return fmt.Sprintf("a%dp%d", i, j)
+}
+
+type output struct {
+	file *os.File
+	w    *bufio.Writer
+}
+
+func create(path string) (*output, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output{file: file, w: bufio.NewWriter(file)}, nil
+}
+
+func (f *output) write(v any) error {
+	return writeLine(f.w, v)
+}
+
+func writeLine(w *bufio.Writer, v any) error {
+	if err := json.MarshalWrite(w, v); err != nil {
+		return err
+	}
+	return w.WriteByte('\n')
+}
+
+func (f *output) close() error {
+	if err := f.w.Flush(); err != nil {
+		_ = f.file.Close()
+		return err
+	}
+	return f.file.Close()
+}
@@ -0,0 +1,305 @@
+package export_test
+
+import (
+	"encoding/json/v2"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/donderom/sqwat/export"
+	"github.com/donderom/sqwat/squad"
+)
+
+func data() *squad.SQuAD {
+	return &squad.SQuAD{
+		Version: "v2.0",
+		Articles: []squad.Article{
+			{
+				Name: "Go",
+				Paragraphs: []squad.Paragraph{
+					{
+						Context: "Go was designed at Google.",
+						QAs: []squad.QA{
+							{
+								Id:             "q1",
+								Question:       "Where was Go designed?",
+								CorrectAnswers: []squad.Answer{{Text: "Google", Start: 19}},
+							},
+							{Id: "q2", Question: "When was Go retired?", Impossible: true},
+						},
+					},
+				},
+			},
+			{
+				Name: "Golang",
+				Paragraphs: []squad.Paragraph{
+					{
+						Context: "Go was designed at Google.",
+						QAs: []squad.QA{
+							{
+								Id:             "q3",
+								Question:       "Who designed Go?",
+								CorrectAnswers: []squad.Answer{{Text: "Google", Start: 19}},
+							},
+							{
+								Id:             "q1",
+								Question:       "Where was Go designed?",
+								CorrectAnswers: []squad.Answer{{Text: "Google", Start: 19}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func read(t *testing.T, path ...string) string {
+	t.Helper()
+
+	b, err := os.ReadFile(filepath.Join(path...))
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestParagraphID(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "a1p2", export.ParagraphID(export.IndexIDs, 1, 2, "Go"))
+	assert.Equal(t,
+		export.ParagraphID(export.HashIDs, 0, 0, "Go"),
+		export.ParagraphID(export.HashIDs, 3, 4, "Go"),
+	)
+	assert.NotEqual(t,
+		export.ParagraphID(export.HashIDs, 0, 0, "Go"),
+		export.ParagraphID(export.HashIDs, 0, 0, "Rust"),
+	)
+}
+
+func TestBEIR(t *testing.T) {
+	t.Parallel()
+
+	t.Run("index", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		stats, err := export.BEIR(dir, data(), export.IndexIDs)
+		require.NoError(t, err)
+		assert.Equal(t, export.BEIRStats{Documents: 2, Queries: 2, Impossible: 1, Duplicates: 1}, stats)
+
+		assert.Equal(t,
+			`{"_id":"a0p0","title":"Go","text":"Go was designed at Google."}`+"\n"+
+				`{"_id":"a1p0","title":"Golang","text":"Go was designed at Google."}`+"\n",
+			read(t, dir, "corpus.jsonl"),
+		)
+		assert.Equal(t,
+			`{"_id":"q1","text":"Where was Go designed?"}`+"\n"+
+				`{"_id":"q3","text":"Who designed Go?"}`+"\n",
+			read(t, dir, "queries.jsonl"),
+		)
+		assert.Equal(t,
+			"query-id\tcorpus-id\tscore\nq1\ta0p0\t1\nq3\ta1p0\t1\n",
+			read(t, dir, "qrels", "test.tsv"),
+		)
+	})
+
+	t.Run("hash", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		stats, err := export.BEIR(dir, data(), export.HashIDs)
+		require.NoError(t, err)
+		assert.Equal(t, 1, stats.Documents)
+
+		id := export.ParagraphID(export.HashIDs, 0, 0, "Go was designed at Google.")
+		qrels := strings.Split(strings.TrimSpace(read(t, dir, "qrels", "test.tsv")), "\n")
+		assert.Equal(t, []string{"query-id\tcorpus-id\tscore", "q1\t" + id + "\t1", "q3\t" + id + "\t1"}, qrels)
+	})
+}
+
+func TestBM25(t *testing.T) {
+	t.Parallel()
+
+	index := export.NewBM25([]string{
+		"Go was designed at Google.",
+		"Rust was designed at Mozilla.",
+		"Python is a language.",
+	})
+
+	hits := index.Search("Who designed Rust?", 5, nil)
+	require.Len(t, hits, 2)
+	assert.Equal(t, 1, hits[0].Doc)
+	assert.Equal(t, 0, hits[1].Doc)
+	assert.Greater(t, hits[0].Score, hits[1].Score)
+
+	hits = index.Search("Who designed Rust?", 5, func(doc int) bool { return doc == 1 })
+	require.Len(t, hits, 1)
+	assert.Equal(t, 0, hits[0].Doc)
+
+	assert.Empty(t, index.Search("unknown", 5, nil))
+}
+
+func TestDPR(t *testing.T) {
+	t.Parallel()
+
+	s := &squad.SQuAD{
+		Version: "v2.0",
+		Articles: []squad.Article{
+			{
+				Name: "Languages",
+				Paragraphs: []squad.Paragraph{
+					{
+						Context: "Go was designed at Google.",
+						QAs: []squad.QA{
+							{
+								Id:             "q1",
+								Question:       "Where was Go designed?",
+								CorrectAnswers: []squad.Answer{{Text: "Google", Start: 19}},
+							},
+							{Id: "q2", Question: "When was Go retired?", Impossible: true},
+						},
+					},
+					{Context: "Rust was designed at Mozilla.", QAs: []squad.QA{}},
+					{Context: "Go runs at Google scale.", QAs: []squad.QA{}},
+				},
+			},
+		},
+	}
+
+	var out strings.Builder
+	stats, err := export.DPR(&out, s, 2, export.IndexIDs)
+	require.NoError(t, err)
+	assert.Equal(t, export.DPRStats{Questions: 1, Impossible: 1, Short: 1}, stats)
+
+	var entries []struct {
+		Question     string   `json:"question"`
+		Answers      []string `json:"answers"`
+		PositiveCtxs []struct {
+			PassageId string `json:"passage_id"`
+		} `json:"positive_ctxs"`
+		NegativeCtxs     []any `json:"negative_ctxs"`
+		HardNegativeCtxs []struct {
+			Text      string `json:"text"`
+			PassageId string `json:"passage_id"`
+		} `json:"hard_negative_ctxs"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out.String()), &entries))
+	require.Len(t, entries, 1)
+
+	entry := entries[0]
+	assert.Equal(t, []string{"Google"}, entry.Answers)
+	require.Len(t, entry.PositiveCtxs, 1)
+	assert.Equal(t, "a0p0", entry.PositiveCtxs[0].PassageId)
+	assert.NotNil(t, entry.NegativeCtxs)
+	// The third paragraph mentions the answer and is no negative
+	require.Len(t, entry.HardNegativeCtxs, 1)
+	assert.Equal(t, "a0p1", entry.HardNegativeCtxs[0].PassageId)
+}
+
+func TestDPREmptyAnswer(t *testing.T) {
+	t.Parallel()
+
+	s := &squad.SQuAD{
+		Articles: []squad.Article{
+			{
+				Name: "Languages",
+				Paragraphs: []squad.Paragraph{
+					{
+						Context: "Go was designed at Google.",
+						QAs: []squad.QA{
+							{Id: "q1", Question: "Where was Go designed?", CorrectAnswers: []squad.Answer{{Text: ""}}},
+						},
+					},
+					{Context: "Go runs at Google scale.", QAs: []squad.QA{}},
+				},
+			},
+		},
+	}
+
+	var out strings.Builder
+	stats, err := export.DPR(&out, s, 1, export.IndexIDs)
+	require.NoError(t, err)
+	assert.Equal(t, export.DPRStats{Questions: 1}, stats)
+
+	var entries []struct {
+		HardNegativeCtxs []struct {
+			PassageId string `json:"passage_id"`
+		} `json:"hard_negative_ctxs"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out.String()), &entries))
+	require.Len(t, entries, 1)
+	require.Len(t, entries[0].HardNegativeCtxs, 1)
+	assert.Equal(t, "a0p1", entries[0].HardNegativeCtxs[0].PassageId)
+}
+
+func TestBIO(t *testing.T) {
+	t.Parallel()
+
+	s := &squad.SQuAD{
+		Version: "v2.0",
+		Articles: []squad.Article{
+			{
+				Name: "Go",
+				Paragraphs: []squad.Paragraph{
+					{
+						Context: "Go was designed at Google Inc.",
+						QAs: []squad.QA{
+							{
+								Id:             "q1",
+								Question:       "Where was Go designed?",
+								CorrectAnswers: []squad.Answer{{Text: "Google Inc", Start: 19}},
+							},
+							{Id: "q2", Question: "When was Go retired?", Impossible: true},
+							{
+								Id:             "q3",
+								Question:       "Who designed Go?",
+								CorrectAnswers: []squad.Answer{{Text: "Goo", Start: 19}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	t.Run("conll", func(t *testing.T) {
+		t.Parallel()
+
+		var out strings.Builder
+		err := export.BIO(&out, s, export.CoNLL)
+
+		unmapped, ok := errors.AsType[squad.Unmapped](err)
+		require.True(t, ok)
+		assert.Equal(t, squad.Unmapped{{Record: "q3", Reason: `answer "Goo" not on token boundaries`}}, unmapped)
+
+		assert.Equal(t,
+			"# id = q1\n# question = Where was Go designed?\n"+
+				"Go\tO\nwas\tO\ndesigned\tO\nat\tO\nGoogle\tB-ANS\nInc\tI-ANS\n.\tO\n\n"+
+				"# id = q2\n# question = When was Go retired?\n"+
+				"Go\tO\nwas\tO\ndesigned\tO\nat\tO\nGoogle\tO\nInc\tO\n.\tO\n\n",
+			out.String(),
+		)
+	})
+
+	t.Run("jsonl", func(t *testing.T) {
+		t.Parallel()
+
+		var out strings.Builder
+		err := export.BIO(&out, s, export.BIOJSONL)
+		require.Error(t, err)
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		require.Len(t, lines, 2)
+		assert.Equal(t,
+			`{"id":"q1","question":"Where was Go designed?",`+
+				`"tokens":["Go","was","designed","at","Google","Inc","."],`+
+				`"labels":["O","O","O","O","B-ANS","I-ANS","O"]}`,
+			lines[0],
+		)
+	})
+}
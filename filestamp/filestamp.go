@@ -0,0 +1,77 @@
+package filestamp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// Stamp is the state of a file when it was last read or written.
+type Stamp struct {
+	ModTime time.Time
+	Size    int64
+	Sum     []byte
+}
+
+func NewHash() hash.Hash {
+	return sha256.New()
+}
+
+// Of stamps the file at path with the sum of the bytes just read or
+// written through NewHash.
+func Of(path string, sum []byte) (Stamp, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Stamp{}, err
+	}
+
+	return Stamp{ModTime: info.ModTime(), Size: info.Size(), Sum: sum}, nil
+}
+
+// Changed tells whether the file differs from the stamp. A file with
+// the same size and modification time is not read, a missing one is
+// not changed as there is nothing to overwrite.
+func (s Stamp) Changed(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if info.Size() == s.Size && info.ModTime().Equal(s.ModTime) {
+		return false, nil
+	}
+
+	sum, err := Sum(path)
+	if err != nil {
+		return false, err
+	}
+
+	return !bytes.Equal(sum, s.Sum), nil
+}
+
+func Sum(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	h := NewHash()
+	if _, err = io.Copy(h, file); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	if err = file.Close(); err != nil {
+		return nil, err
+	}
+
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,53 @@
+package filestamp_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/donderom/sqwat/filestamp"
+)
+
+func stamp(t *testing.T, path string) filestamp.Stamp {
+	t.Helper()
+
+	sum, err := filestamp.Sum(path)
+	require.NoError(t, err)
+
+	s, err := filestamp.Of(path, sum)
+	require.NoError(t, err)
+	return s
+}
+
+func TestChanged(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "data.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"data": []}`), 0o644))
+	s := stamp(t, path)
+
+	changed, err := s.Changed(path)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	// Touched but the same content
+	later := s.ModTime.Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+	changed, err = s.Changed(path)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"data": [1]}`), 0o644))
+	changed, err = s.Changed(path)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	require.NoError(t, os.Remove(path))
+	changed, err = s.Changed(path)
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
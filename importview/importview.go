@@ -0,0 +1,313 @@
+package importview
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/donderom/sqwat/app"
+	"github.com/donderom/sqwat/keyset"
+	"github.com/donderom/sqwat/qna"
+	"github.com/donderom/sqwat/squad"
+	"github.com/donderom/sqwat/style"
+	"github.com/donderom/sqwat/tabular"
+	"github.com/donderom/sqwat/teax"
+	"github.com/donderom/sqwat/text"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/donderom/bubblon"
+)
+
+type decision uint8
+
+const (
+	pending decision = iota
+	answered
+	impossible
+	discarded
+)
+
+type Item struct {
+	tabular.Review
+	decision decision
+	start    int
+}
+
+func (i Item) Description() string {
+	switch i.decision {
+	case answered:
+		return fmt.Sprintf("%s · answer at %d", i.Review.Description(), i.start)
+	case impossible:
+		return i.Review.Description() + " · impossible"
+	case discarded:
+		return i.Review.Description() + " · discarded"
+	}
+	return i.Review.Description()
+}
+
+type Import struct {
+	list       teax.ViewList[Item, text.Segment]
+	items      []Item
+	result     tabular.Result
+	data       *squad.SQuAD
+	filename   string
+	dataset    teax.Dataset
+	standalone bool
+}
+
+var _ tea.Model = Import{}
+
+var (
+	keys []key.Binding = []key.Binding{
+		keyset.NewEnter("pick answer"),
+		keyset.Impossible,
+		keyset.Discard,
+		keyset.Save,
+		keyset.Esc,
+	}
+
+	fullKeys []key.Binding = []key.Binding{
+		keyset.Next,
+		keyset.Prev,
+	}
+
+	delegate teax.Delegate[Item] = teax.Delegate[Item]{
+		Style:           reviewStyle,
+		ItemName:        "review",
+		ShowDescription: true,
+		ShortHelpKeys:   keys,
+		FullHelpKeys:    slices.Concat(keys, fullKeys),
+	}
+
+	reviewStyle teax.StyleFunc[Item] = teax.StyleFunc[Item](
+		func(defaultStyles teax.Styles) teax.ItemStyles[Item] {
+			return func(item Item) teax.Styles {
+				styles := defaultStyles
+				if item.decision == pending {
+					border := style.Border.Error
+					styles.NormalDesc = border.Apply(styles.NormalDesc)
+					styles.SelectedDesc = border.Apply(styles.SelectedDesc)
+				}
+				return styles
+			}
+		})
+)
+
+// New reviews the rows of path that could not be mapped as is, the
+// imported questions are appended to data on save.
+func New(
+	path string,
+	result tabular.Result,
+	data *squad.SQuAD,
+	filename string,
+	dataset teax.Dataset,
+) Import {
+	items := make([]Item, len(result.Reviews))
+	for i, r := range result.Reviews {
+		items[i] = Item{Review: r}
+	}
+
+	title := fmt.Sprintf("Import %s (%d of %d rows to review)", path, len(items), result.Rows)
+
+	return Import{
+		list:     teax.NewViewList[text.Segment](items, title, delegate),
+		items:    items,
+		result:   result,
+		data:     data,
+		filename: filename,
+		dataset:  dataset,
+	}
+}
+
+// Standalone makes the screen quit the app on esc as there is
+// nothing to return to.
+func (m Import) Standalone() Import {
+	m.standalone = true
+	return m
+}
+
+func (m Import) Init() tea.Cmd {
+	return nil
+}
+
+func (m Import) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.Resize(msg)
+		m.updateContent()
+		return m, nil
+
+	case qna.Disambiguated:
+		return m.decide(answered, msg.Start())
+
+	case tea.KeyMsg:
+		if m.list.Unfiltered() {
+			switch {
+			case key.Matches(msg, keyset.Esc):
+				if m.standalone {
+					return m, tea.Quit
+				}
+				return m, bubblon.Close
+
+			case key.Matches(msg, keyset.Quit):
+				return m, tea.Quit
+
+			case key.Matches(msg, keyset.View):
+				if m.list.ItemSelected() {
+					item := m.items[m.list.GlobalIndex()]
+					if len(item.Indices) == 0 {
+						return m, m.list.NewStatus(style.Error.Render("No answer in the context"))
+					}
+					ambi := qna.NewAmbi(item.QA.Question, []rune(item.Context), item.Answer, item.Indices)
+					return m, bubblon.Open(ambi)
+				}
+
+			case key.Matches(msg, keyset.Impossible):
+				return m.decide(impossible, 0)
+
+			case key.Matches(msg, keyset.Discard):
+				return m.decide(discarded, 0)
+
+			case key.Matches(msg, keyset.Save):
+				return m.save()
+			}
+		}
+	}
+
+	m.list, cmd = m.list.Update(msg)
+	m.updateContent()
+	return m, cmd
+}
+
+func (m Import) View() string {
+	helpView := m.list.Help.View(m.list)
+	m.list.DecreaseHeight(lipgloss.Height(helpView))
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		style.Top.Render(m.list.View()),
+		m.list.Viewport.View(),
+		style.Bot.Render(helpView),
+	)
+}
+
+func (m Import) decide(d decision, start int) (tea.Model, tea.Cmd) {
+	if !m.list.ItemSelected() {
+		return m, nil
+	}
+
+	index := m.list.GlobalIndex()
+	m.items[index].decision = d
+	m.items[index].start = start
+	cmd := m.list.SetItem(index, m.items[index])
+	m.updateContent()
+	return m, cmd
+}
+
+func (m Import) save() (tea.Model, tea.Cmd) {
+	left := 0
+	for _, item := range m.items {
+		if item.decision == pending {
+			left++
+		}
+	}
+
+	if left > 0 {
+		return m, m.list.NewStatus(style.Error.Render(
+			fmt.Sprintf("%d rows still to review", left),
+		))
+	}
+
+	// Reviewed rows go to a copy so that a failed save can be retried
+	reviewed := &squad.SQuAD{Articles: clone(m.result.Data.Articles)}
+	for _, item := range m.items {
+		switch item.decision {
+		case answered:
+			item.AddTo(reviewed, item.Answered(item.start))
+		case impossible:
+			item.AddTo(reviewed, item.Impossible())
+		}
+	}
+
+	backup := clone(m.data.Articles)
+	tabular.Append(m.data, reviewed)
+
+	var err error
+	if journaler, ok := m.dataset.(teax.Journaler); ok {
+		edits := appended(m.data, backup)
+		err = journaler.Journal(edits...)
+		if err != nil && !teax.IsWarning(err) {
+			m.data.Articles = backup
+			return m, m.list.NewStatus(style.Error.Render(err.Error()))
+		}
+	}
+
+	var cmd tea.Cmd
+	if saver, ok := m.dataset.(teax.Saver); ok {
+		cmd = saver.Schedule(func() {
+			m.data.Articles = backup
+		})
+	}
+	if err != nil {
+		cmd = tea.Batch(cmd, bubblon.Cmd(teax.Error{Err: err}))
+	}
+	return m, tea.Sequence(bubblon.ReplaceAll(app.New(m.data, m.filename, m.dataset)), cmd)
+}
+
+// appended finds the items added to data since it had the articles of
+// before, the ones within added items come with them.
+func appended(data *squad.SQuAD, before []squad.Article) []teax.Edit {
+	var edits []teax.Edit
+	for i := range data.Articles {
+		if i >= len(before) {
+			edits = append(edits, teax.Edit{Change: teax.Creation, Coll: data, Index: i})
+			continue
+		}
+
+		article := data.At(i)
+		for j := range article.Paragraphs {
+			if j >= len(before[i].Paragraphs) {
+				edits = append(edits, teax.Edit{Change: teax.Creation, Coll: article, Index: j})
+				continue
+			}
+
+			paragraph := article.At(j)
+			for k := len(before[i].Paragraphs[j].QAs); k < len(paragraph.QAs); k++ {
+				edits = append(edits, teax.Edit{Change: teax.Creation, Coll: paragraph, Index: k})
+			}
+		}
+	}
+	return edits
+}
+
+func (m *Import) updateContent() {
+	if !m.list.ItemSelected() {
+		m.list.Viewport.SetContent("")
+		return
+	}
+
+	item := m.items[m.list.GlobalIndex()]
+	context := []rune(item.Context)
+	length := len([]rune(item.Answer))
+
+	var segments []text.Segment
+	for _, start := range item.Indices {
+		if item.decision != answered || start == item.start {
+			segments = append(segments, text.Segment{Start: start, End: start + length})
+		}
+	}
+
+	m.list.Viewport.Highlight(context, segments, style.Highlight)
+}
+
+// clone copies articles deep enough to restore them after appending.
+func clone(articles []squad.Article) []squad.Article {
+	result := slices.Clone(articles)
+	for i := range result {
+		result[i].Paragraphs = slices.Clone(result[i].Paragraphs)
+	}
+	return result
+}
@@ -0,0 +1,44 @@
+package importview
+
+import (
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/donderom/bubblon"
+
+	"github.com/donderom/sqwat/squad"
+	"github.com/donderom/sqwat/tabular"
+	"github.com/donderom/sqwat/teax"
+)
+
+// NewPicker picks a CSV or TSV file with the default columns to import.
+func NewPicker(data *squad.SQuAD, filename string, dataset teax.Dataset) teax.Picker {
+	open := func(path string) tea.Cmd {
+		return func() tea.Msg {
+			file, err := os.Open(path)
+			if err != nil {
+				return teax.PickFailed{Err: err}
+			}
+
+			result, err := tabular.Read(file, tabular.Separator(path), tabular.DefaultColumns)
+			if err != nil {
+				_ = file.Close()
+				return teax.PickFailed{Err: err}
+			}
+
+			if err = file.Close(); err != nil {
+				return teax.PickFailed{Err: err}
+			}
+
+			return bubblon.Replace(New(path, result, data, filename, dataset))()
+		}
+	}
+
+	return teax.NewPicker(
+		"Pick a CSV or TSV file to import:",
+		filepath.Dir(filename),
+		[]string{".csv", ".tsv"},
+		open,
+	).Closable()
+}
@@ -0,0 +1,330 @@
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/donderom/sqwat/squad"
+)
+
+type Kind string
+
+const (
+	Created Kind = "created"
+	Updated Kind = "updated"
+	Deleted Kind = "deleted"
+)
+
+// Op is an edit of the item at Path, the indices of the article,
+// paragraph, question and answer as deep as the item is.
+type Op struct {
+	Kind  Kind           `json:"op"`
+	Path  []int          `json:"path"`
+	Value jsontext.Value `json:"value,omitzero"`
+}
+
+// header starts a journal with the sum of the file the edits are made
+// to, they are saved already once the file is written with others.
+type header struct {
+	Sum []byte `json:"sum"`
+}
+
+// Path is the journal of edits not saved to filename yet.
+func Path(filename string) string {
+	return filename + ".journal"
+}
+
+// NewOp journals the edit of the item at index of coll, one of the
+// collections of data.
+func NewOp(data *squad.SQuAD, kind Kind, coll any, index int) (Op, error) {
+	path, ok := Locate(data, coll)
+	if !ok {
+		return Op{}, errors.New("edited item is not in the dataset")
+	}
+
+	op := Op{Kind: kind, Path: append(path, index)}
+	if kind == Deleted {
+		return op, nil
+	}
+
+	var item any
+	switch c := coll.(type) {
+	case *squad.SQuAD:
+		item = c.Get(index)
+	case *squad.Article:
+		item = c.Get(index)
+	case *squad.Paragraph:
+		item = c.Get(index)
+	case *squad.QA:
+		item = c.Get(index)
+	}
+
+	value, err := json.Marshal(item)
+	op.Value = value
+	return op, err
+}
+
+// Locate finds the path of coll within data.
+func Locate(data *squad.SQuAD, coll any) ([]int, bool) {
+	for i := range data.Articles {
+		a := &data.Articles[i]
+		if a == coll {
+			return []int{i}, true
+		}
+
+		for j := range a.Paragraphs {
+			p := &a.Paragraphs[j]
+			if p == coll {
+				return []int{i, j}, true
+			}
+
+			for k := range p.QAs {
+				if &p.QAs[k] == coll {
+					return []int{i, j, k}, true
+				}
+			}
+		}
+	}
+
+	return []int{}, data == coll
+}
+
+// Append writes the ops through to the journal of filename at once,
+// sum is the one of the file they are made to.
+func Append(filename string, sum []byte, ops ...Op) error {
+	file, err := os.OpenFile(Path(filename), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	var lines []byte
+	if err == nil && info.Size() == 0 {
+		lines, err = json.Marshal(header{Sum: sum})
+		lines = append(lines, '\n')
+	}
+	for _, op := range ops {
+		if err != nil {
+			break
+		}
+		var line []byte
+		line, err = json.Marshal(op)
+		lines = append(append(lines, line...), '\n')
+	}
+
+	if err == nil {
+		_, err = file.Write(lines)
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// Clear drops the journal once the edits are saved to filename.
+func Clear(filename string) error {
+	err := os.Remove(Path(filename))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Size is how much is journaled for filename so far.
+func Size(filename string) (int64, error) {
+	info, err := os.Stat(Path(filename))
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// Trim drops the edits journaled up to size once they are saved to
+// filename with sum, the ones journaled while saving are kept.
+func Trim(filename string, size int64, sum []byte) error {
+	journal, err := os.ReadFile(Path(filename))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if int64(len(journal)) <= size {
+		return Clear(filename)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(Path(filename))+".*")
+	if err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(header{Sum: sum})
+	if err == nil {
+		_, err = file.Write(append(append(line, '\n'), journal[size:]...))
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), Path(filename))
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+	}
+	return err
+}
+
+// Pending reads the edits journaled for filename with sum, the ones
+// journaled for another sum are saved already.
+func Pending(filename string, sum []byte) ([]Op, error) {
+	ops, journaled, err := read(Path(filename))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil || !bytes.Equal(journaled, sum) {
+		return nil, err
+	}
+	return ops, nil
+}
+
+// Read loads the ops of a journal. A line cut short by a crash ends
+// the journal.
+func Read(path string) ([]Op, error) {
+	ops, _, err := read(path)
+	return ops, err
+}
+
+// read loads the ops of a journal along with the sum of its header.
+func read(path string) ([]Op, []byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 64*1024*1024)
+
+	var h header
+	if !scanner.Scan() || json.Unmarshal(scanner.Bytes(), &h, json.RejectUnknownMembers(true)) != nil {
+		return nil, nil, scanner.Err()
+	}
+
+	var ops []Op
+	for scanner.Scan() {
+		var op Op
+		if err = json.Unmarshal(scanner.Bytes(), &op); err != nil {
+			break
+		}
+		ops = append(ops, op)
+	}
+
+	return ops, h.Sum, scanner.Err()
+}
+
+type collection[Item any] interface {
+	Insert(index int, item Item)
+	Update(index int, item Item)
+	Remove(index int)
+	All() []Item
+}
+
+// Replay applies the ops to data, it's left as is on error.
+func Replay(data *squad.SQuAD, ops []Op) error {
+	result := data.Clone()
+	for i, op := range ops {
+		if err := replay(result, op); err != nil {
+			return fmt.Errorf("edit %d: %w", i+1, err)
+		}
+	}
+
+	*data = *result
+	return nil
+}
+
+func replay(data *squad.SQuAD, op Op) error {
+	if len(op.Path) == 0 {
+		return errors.New("empty path")
+	}
+
+	index, parents := op.Path[len(op.Path)-1], op.Path[:len(op.Path)-1]
+	if len(parents) == 0 {
+		return apply(data, index, op)
+	}
+
+	if parents[0] < 0 || parents[0] >= len(data.Articles) {
+		return errOutOfRange
+	}
+	article := data.At(parents[0])
+	if len(parents) == 1 {
+		return apply(article, index, op)
+	}
+
+	if parents[1] < 0 || parents[1] >= len(article.Paragraphs) {
+		return errOutOfRange
+	}
+	paragraph := article.At(parents[1])
+	if len(parents) == 2 {
+		return apply(paragraph, index, op)
+	}
+
+	if parents[2] < 0 || parents[2] >= len(paragraph.QAs) {
+		return errOutOfRange
+	}
+	if len(parents) == 3 {
+		return apply(paragraph.At(parents[2]), index, op)
+	}
+
+	return fmt.Errorf("path %v is too deep", op.Path)
+}
+
+var errOutOfRange = errors.New("path out of range")
+
+func apply[Item any](coll collection[Item], index int, op Op) error {
+	size := len(coll.All())
+	if op.Kind == Created {
+		size++
+	}
+	if index < 0 || index >= size {
+		return errOutOfRange
+	}
+
+	var item Item
+	if op.Kind != Deleted {
+		if err := json.Unmarshal(op.Value, &item); err != nil {
+			return err
+		}
+	}
+
+	switch op.Kind {
+	case Created:
+		coll.Insert(index, item)
+	case Updated:
+		coll.Update(index, item)
+	case Deleted:
+		coll.Remove(index)
+	default:
+		return fmt.Errorf("unknown op %q", op.Kind)
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package journal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/donderom/sqwat/journal"
+	"github.com/donderom/sqwat/squad"
+)
+
+func dataset() *squad.SQuAD {
+	return &squad.SQuAD{
+		Version: "v2.0",
+		Articles: []squad.Article{{
+			Name: "Go",
+			Paragraphs: []squad.Paragraph{{
+				Context: "Go was designed at Google.",
+				QAs: []squad.QA{{
+					Id:             "q1",
+					Question:       "Who designed Go?",
+					CorrectAnswers: []squad.Answer{{Text: "Google", Start: 19}},
+				}},
+			}},
+		}},
+	}
+}
+
+func TestLocate(t *testing.T) {
+	t.Parallel()
+
+	data := dataset()
+	article := data.At(0)
+	paragraph := article.At(0)
+
+	for name, tc := range map[string]struct {
+		coll any
+		path []int
+	}{
+		"dataset":   {data, []int{}},
+		"article":   {article, []int{0}},
+		"paragraph": {paragraph, []int{0, 0}},
+		"question":  {paragraph.At(0), []int{0, 0, 0}},
+	} {
+		path, ok := journal.Locate(data, tc.coll)
+		assert.True(t, ok, name)
+		assert.Equal(t, tc.path, path, name)
+	}
+
+	_, ok := journal.Locate(data, &squad.Article{})
+	assert.False(t, ok)
+}
+
+func TestReplay(t *testing.T) {
+	t.Parallel()
+
+	data, edited := dataset(), dataset()
+	var ops []journal.Op
+	record := func(kind journal.Kind, coll any, index int) {
+		op, err := journal.NewOp(edited, kind, coll, index)
+		require.NoError(t, err)
+		ops = append(ops, op)
+	}
+
+	qa := edited.At(0).At(0).At(0)
+	qa.Add(squad.Answer{Text: "Go", Start: 0})
+	record(journal.Created, qa, 1)
+
+	paragraph := edited.At(0).At(0)
+	paragraph.Update(0, squad.QA{Id: "q1", Question: "Who made Go?", CorrectAnswers: qa.CorrectAnswers})
+	record(journal.Updated, paragraph, 0)
+
+	edited.Add(squad.Article{Name: "Rust", Paragraphs: []squad.Paragraph{}})
+	record(journal.Created, edited, 1)
+
+	edited.Insert(0, squad.Article{Name: "C", Paragraphs: []squad.Paragraph{}})
+	record(journal.Created, edited, 0)
+
+	edited.Remove(2)
+	record(journal.Deleted, edited, 2)
+
+	require.NoError(t, journal.Replay(data, ops))
+	assert.Equal(t, edited, data)
+}
+
+func TestReplayOutOfRange(t *testing.T) {
+	t.Parallel()
+
+	data := dataset()
+	ops := []journal.Op{
+		{Kind: journal.Deleted, Path: []int{0}},
+		{Kind: journal.Deleted, Path: []int{0, 0}},
+	}
+
+	assert.ErrorContains(t, journal.Replay(data, ops), "edit 2")
+	assert.Equal(t, dataset(), data)
+}
+
+func TestPending(t *testing.T) {
+	t.Parallel()
+
+	filename := filepath.Join(t.TempDir(), "train.json")
+	sum := []byte("saved")
+
+	ops, err := journal.Pending(filename, sum)
+	require.NoError(t, err)
+	assert.Empty(t, ops)
+
+	op := journal.Op{Kind: journal.Deleted, Path: []int{0}}
+	require.NoError(t, journal.Append(filename, sum, op))
+	require.NoError(t, journal.Append(filename, sum, op))
+
+	ops, err = journal.Pending(filename, sum)
+	require.NoError(t, err)
+	assert.Equal(t, []journal.Op{op, op}, ops)
+
+	// A crash may cut the last line short
+	file, err := os.OpenFile(journal.Path(filename), os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"op":"del`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	ops, err = journal.Pending(filename, sum)
+	require.NoError(t, err)
+	assert.Len(t, ops, 2)
+
+	// The file saved since has the edits already
+	ops, err = journal.Pending(filename, []byte("saved again"))
+	require.NoError(t, err)
+	assert.Empty(t, ops)
+
+	require.NoError(t, journal.Clear(filename))
+	assert.NoFileExists(t, journal.Path(filename))
+	require.NoError(t, journal.Clear(filename))
+}
+
+func TestTrim(t *testing.T) {
+	t.Parallel()
+
+	filename := filepath.Join(t.TempDir(), "train.json")
+	saved := journal.Op{Kind: journal.Deleted, Path: []int{0}}
+	require.NoError(t, journal.Append(filename, []byte("old"), saved))
+
+	size, err := journal.Size(filename)
+	require.NoError(t, err)
+
+	later := journal.Op{Kind: journal.Deleted, Path: []int{1}}
+	require.NoError(t, journal.Append(filename, []byte("old"), later))
+
+	// The edits journaled while saving are made to the file saved
+	require.NoError(t, journal.Trim(filename, size, []byte("new")))
+	ops, err := journal.Pending(filename, []byte("new"))
+	require.NoError(t, err)
+	assert.Equal(t, []journal.Op{later}, ops)
+
+	size, err = journal.Size(filename)
+	require.NoError(t, err)
+	require.NoError(t, journal.Trim(filename, size, []byte("newer")))
+	assert.NoFileExists(t, journal.Path(filename))
+}
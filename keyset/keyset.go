@@ -81,12 +81,94 @@ var (
 		key.WithHelp("s", "status"),
 	)
 
+	Duplicates key.Binding = key.NewBinding(
+		key.WithKeys("D"),
+		key.WithHelp("D", "near duplicates"),
+	)
+
+	Intended key.Binding = key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "mark as intended"),
+	)
+
+	Import key.Binding = key.NewBinding(
+		key.WithKeys("I"),
+		key.WithHelp("I", "import CSV/TSV"),
+	)
+
+	Impossible key.Binding = key.NewBinding(
+		key.WithKeys("i"),
+		key.WithHelp("i", "impossible"),
+	)
+
+	Discard key.Binding = key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "discard"),
+	)
+
 	GenerateUID key.Binding = key.NewBinding(
 		key.WithKeys("u"),
 		key.WithHelp("u", "generate UID"),
 	)
+
+	ViewOther key.Binding = key.NewBinding(
+		key.WithKeys("tab"),
+		key.WithHelp("tab", "view in second file"),
+	)
+
+	Ours key.Binding = key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "pick ours"),
+	)
+
+	Theirs key.Binding = key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "pick theirs"),
+	)
+
+	Reload key.Binding = key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "reload from disk"),
+	)
+
+	Overwrite key.Binding = key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "overwrite"),
+	)
+
+	Merge key.Binding = key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "merge"),
+	)
+
+	AuditLog key.Binding = key.NewBinding(
+		key.WithKeys("L"),
+		key.WithHelp("L", "audit log"),
+	)
+
+	History key.Binding = key.NewBinding(
+		key.WithKeys("H"),
+		key.WithHelp("H", "history"),
+	)
+
+	ExportPatch key.Binding = key.NewBinding(
+		key.WithKeys("P"),
+		key.WithHelp("P", "export edits as patch"),
+	)
 )
 
+// Mutating bindings change the dataset, read-only mode hides them.
+var Mutating = []key.Binding{
+	Create,
+	Edit,
+	Delete,
+	Add,
+	Invert,
+	GenerateUID,
+	Import,
+	Intended,
+}
+
 func NewEnter(desc string) key.Binding {
 	return key.NewBinding(
 		key.WithKeys("enter"),
@@ -113,7 +195,9 @@ func Bindings(bindings ...key.Binding) KeyMap {
 var KeyMaps = struct {
 	Confirm KeyMap
 	Edit    KeyMap
+	Unsaved KeyMap
 }{
 	Confirm: Bindings(Ok, Esc),
 	Edit:    Bindings(Save, Esc),
+	Unsaved: Bindings(Save, Discard, Esc),
 }
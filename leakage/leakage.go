@@ -0,0 +1,213 @@
+package leakage
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+
+	"github.com/donderom/sqwat/squad"
+	"github.com/donderom/sqwat/text"
+	"github.com/donderom/sqwat/validation"
+)
+
+type Kind uint8
+
+const (
+	ExactContext Kind = iota
+	NearContext
+	DupQuestion
+)
+
+func (k Kind) String() string {
+	switch k {
+	case ExactContext:
+		return "Identical context"
+	case NearContext:
+		return "Similar context"
+	case DupQuestion:
+		return "Duplicate question"
+	}
+	return "Unknown"
+}
+
+type Options struct {
+	// Size of word n-grams compared
+	N int
+	// Minimal Jaccard similarity of near-duplicate contexts
+	Threshold float64
+}
+
+var DefaultOptions = Options{N: 3, Threshold: 0.8}
+
+type Location struct {
+	Path    validation.Path
+	Article string
+	Text    string
+}
+
+func (l Location) String() string {
+	s := fmt.Sprintf("article %d %q, paragraph %d",
+		l.Path.To(validation.Article)+1,
+		l.Article,
+		l.Path.To(validation.Paragraph)+1,
+	)
+
+	if _, ok := l.Path[validation.Question]; ok {
+		s += fmt.Sprintf(", question %d", l.Path.To(validation.Question)+1)
+	}
+
+	return s
+}
+
+type Hit struct {
+	Kind       Kind
+	Type       validation.ItemType
+	Similarity float64
+	Left       Location
+	Right      Location
+}
+
+var _ list.DefaultItem = Hit{}
+
+func (h Hit) Title() string { return h.Right.Text }
+
+func (h Hit) Description() string {
+	if h.Kind == NearContext {
+		return fmt.Sprintf("%s (%.0f%%)", h.Kind, h.Similarity*100)
+	}
+	return h.Kind.String()
+}
+
+func (h Hit) FilterValue() string { return h.Right.Text }
+
+// Detect finds contexts and questions of right that also appear in left.
+func Detect(left, right *squad.SQuAD, opts Options) []Hit {
+	hits := contexts(left, right, opts)
+	return append(hits, questions(left, right)...)
+}
+
+type paragraph struct {
+	Location
+	shingles map[string]struct{}
+}
+
+func paragraphs(s *squad.SQuAD, n int) []paragraph {
+	var result []paragraph
+	for i, a := range s.Articles {
+		for j, p := range a.Paragraphs {
+			result = append(result, paragraph{
+				Location: Location{
+					Path:    validation.Path{validation.Article: i, validation.Paragraph: j},
+					Article: a.Name,
+					Text:    p.Context,
+				},
+				shingles: text.Shingles(p.Context, n),
+			})
+		}
+	}
+	return result
+}
+
+func contexts(left, right *squad.SQuAD, opts Options) []Hit {
+	lefts := paragraphs(left, opts.N)
+
+	index := make(map[string][]int)
+	for i, p := range lefts {
+		for s := range p.shingles {
+			index[s] = append(index[s], i)
+		}
+	}
+
+	var exact, near []Hit
+
+	for _, r := range paragraphs(right, opts.N) {
+		shared := make(map[int]int)
+		for s := range r.shingles {
+			for _, i := range index[s] {
+				shared[i]++
+			}
+		}
+
+		for i, count := range shared {
+			l := lefts[i]
+
+			if strings.TrimSpace(l.Text) == strings.TrimSpace(r.Text) {
+				exact = append(exact, newHit(ExactContext, validation.Paragraph, 1, l.Location, r.Location))
+				continue
+			}
+
+			similarity := float64(count) / float64(len(l.shingles)+len(r.shingles)-count)
+			if similarity >= opts.Threshold {
+				near = append(near, newHit(NearContext, validation.Paragraph, similarity, l.Location, r.Location))
+			}
+		}
+	}
+
+	sortHits(exact)
+	sortHits(near)
+	return append(exact, near...)
+}
+
+func questions(left, right *squad.SQuAD) []Hit {
+	index := make(map[string][]Location)
+	eachQuestion(left, func(key string, l Location) {
+		index[key] = append(index[key], l)
+	})
+
+	var hits []Hit
+	eachQuestion(right, func(key string, r Location) {
+		for _, l := range index[key] {
+			hits = append(hits, newHit(DupQuestion, validation.Question, 1, l, r))
+		}
+	})
+
+	return hits
+}
+
+func eachQuestion(s *squad.SQuAD, f func(key string, l Location)) {
+	for i, a := range s.Articles {
+		for j, p := range a.Paragraphs {
+			for k, qa := range p.QAs {
+				key := strings.Join(text.Words(qa.Question), " ")
+				if key == "" {
+					continue
+				}
+
+				f(key, Location{
+					Path: validation.Path{
+						validation.Article:   i,
+						validation.Paragraph: j,
+						validation.Question:  k,
+					},
+					Article: a.Name,
+					Text:    qa.Question,
+				})
+			}
+		}
+	}
+}
+
+func newHit(kind Kind, itemType validation.ItemType, similarity float64, left, right Location) Hit {
+	return Hit{
+		Kind:       kind,
+		Type:       itemType,
+		Similarity: similarity,
+		Left:       left,
+		Right:      right,
+	}
+}
+
+func sortHits(hits []Hit) {
+	slices.SortFunc(hits, func(a, b Hit) int {
+		return cmp.Or(
+			cmp.Compare(b.Similarity, a.Similarity),
+			cmp.Compare(a.Right.Path.To(validation.Article), b.Right.Path.To(validation.Article)),
+			cmp.Compare(a.Right.Path.To(validation.Paragraph), b.Right.Path.To(validation.Paragraph)),
+			cmp.Compare(a.Left.Path.To(validation.Article), b.Left.Path.To(validation.Article)),
+			cmp.Compare(a.Left.Path.To(validation.Paragraph), b.Left.Path.To(validation.Paragraph)),
+		)
+	})
+}
@@ -0,0 +1,74 @@
+package leakage_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/donderom/sqwat/leakage"
+	"github.com/donderom/sqwat/squad"
+	"github.com/donderom/sqwat/validation"
+)
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	train := &squad.SQuAD{
+		Articles: []squad.Article{
+			{
+				Name: "Go",
+				Paragraphs: []squad.Paragraph{
+					{
+						Context: "Go is a statically typed, compiled high-level programming language designed at Google.",
+						QAs:     []squad.QA{{Id: "1", Question: "Who designed Go?"}},
+					},
+					{
+						Context: "It is syntactically similar to C.",
+					},
+				},
+			},
+		},
+	}
+
+	dev := &squad.SQuAD{
+		Articles: []squad.Article{
+			{
+				Name: "Rust",
+				Paragraphs: []squad.Paragraph{
+					{Context: "Rust emphasizes performance and type safety."},
+					{
+						Context: "It is syntactically similar to C.",
+						QAs:     []squad.QA{{Id: "2", Question: "who designed go"}},
+					},
+					{
+						Context: "Go is a statically typed, compiled high-level programming language designed at Google by engineers.",
+					},
+				},
+			},
+		},
+	}
+
+	hits := leakage.Detect(train, dev, leakage.DefaultOptions)
+	require.Len(t, hits, 3)
+
+	exact := hits[0]
+	assert.Equal(t, leakage.ExactContext, exact.Kind)
+	assert.Equal(t, 1, exact.Left.Path.To(validation.Paragraph))
+	assert.Equal(t, 1, exact.Right.Path.To(validation.Paragraph))
+
+	near := hits[1]
+	assert.Equal(t, leakage.NearContext, near.Kind)
+	assert.Equal(t, 0, near.Left.Path.To(validation.Paragraph))
+	assert.Equal(t, 2, near.Right.Path.To(validation.Paragraph))
+	assert.InDelta(t, 0.85, near.Similarity, 0.01)
+
+	question := hits[2]
+	assert.Equal(t, leakage.DupQuestion, question.Kind)
+	assert.Equal(t, validation.Question, question.Type)
+	assert.Equal(t, "Who designed Go?", question.Left.Text)
+	assert.Equal(t, "who designed go", question.Right.Text)
+
+	strict := leakage.Options{N: 3, Threshold: 0.9}
+	assert.Len(t, leakage.Detect(train, dev, strict), 2)
+}
@@ -0,0 +1,235 @@
+package leakview
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/donderom/sqwat/article"
+	"github.com/donderom/sqwat/diff"
+	"github.com/donderom/sqwat/keyset"
+	"github.com/donderom/sqwat/leakage"
+	"github.com/donderom/sqwat/paragraph"
+	"github.com/donderom/sqwat/squad"
+	"github.com/donderom/sqwat/style"
+	"github.com/donderom/sqwat/teax"
+	"github.com/donderom/sqwat/text"
+	"github.com/donderom/sqwat/validation"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/donderom/bubblon"
+)
+
+type Item = leakage.Hit
+
+var errGone = errors.New("the item no longer exists")
+
+type File struct {
+	Name    string
+	Data    *squad.SQuAD
+	Dataset teax.Dataset
+}
+
+type Leaks struct {
+	list  teax.ViewList[Item, text.Segment]
+	hits  []Item
+	left  File
+	right File
+	opts  leakage.Options
+	// Set while detecting the hits
+	inSync bool
+}
+
+// detected are the hits found, the edits made since the last detection
+// may have moved or fixed them.
+type detected struct {
+	hits []Item
+}
+
+var _ tea.Model = Leaks{}
+
+var (
+	keys []key.Binding = []key.Binding{
+		keyset.NewEnter("view in first file"),
+		keyset.ViewOther,
+	}
+
+	fullKeys []key.Binding = []key.Binding{
+		keyset.Next,
+		keyset.Prev,
+	}
+
+	delegate teax.Delegate[Item] = teax.Delegate[Item]{
+		Style:           hitStyle,
+		ItemName:        "hit",
+		ShowDescription: true,
+		ShortHelpKeys:   keys,
+		FullHelpKeys:    slices.Concat(keys, fullKeys),
+	}
+
+	hitStyle teax.StyleFunc[Item] = teax.StyleFunc[Item](
+		func(defaultStyles teax.Styles) teax.ItemStyles[Item] {
+			return func(item Item) teax.Styles {
+				styles := defaultStyles
+				if item.Kind == leakage.ExactContext {
+					border := style.Border.Dup
+					styles.NormalDesc = border.Apply(styles.NormalDesc)
+					styles.SelectedDesc = border.Apply(styles.SelectedDesc)
+				}
+				return styles
+			}
+		})
+)
+
+func New(left, right File, opts leakage.Options) Leaks {
+	title := left.Name + " ∩ " + right.Name
+	return Leaks{
+		list:   teax.NewViewList[text.Segment]([]Item{}, title, delegate),
+		left:   left,
+		right:  right,
+		opts:   opts,
+		inSync: true,
+	}
+}
+
+func (m Leaks) Init() tea.Cmd {
+	return tea.Batch(m.list.StartSpinner(), m.detect)
+}
+
+func (m Leaks) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.Resize(msg)
+		m.updateContent()
+		return m, nil
+
+	case detected:
+		m.inSync = false
+		m.list.StopSpinner()
+		m.hits = msg.hits
+		items := make([]list.Item, len(msg.hits))
+		for i, hit := range msg.hits {
+			items[i] = hit
+		}
+		cmd = m.list.SetItems(items)
+		m.updateContent()
+		return m, cmd
+
+	// The hits are found again after the edits made in the files
+	case bubblon.Closed:
+		m.inSync = true
+		return m, tea.Batch(m.list.StartSpinner(), m.detect)
+
+	case tea.KeyMsg:
+		if m.inSync {
+			return m, nil
+		}
+
+		if m.list.Unfiltered() {
+			switch {
+			case key.Matches(msg, keyset.Esc, keyset.Quit):
+				return m, tea.Quit
+
+			case key.Matches(msg, keyset.View):
+				if m.list.ItemSelected() {
+					hit := m.hits[m.list.GlobalIndex()]
+					return m.open(m.left, hit.Left, hit.Type)
+				}
+
+			case key.Matches(msg, keyset.ViewOther):
+				if m.list.ItemSelected() {
+					hit := m.hits[m.list.GlobalIndex()]
+					return m.open(m.right, hit.Right, hit.Type)
+				}
+			}
+		}
+	}
+
+	m.list, cmd = m.list.Update(msg)
+	m.updateContent()
+	return m, cmd
+}
+
+func (m Leaks) View() string {
+	helpView := m.list.Help.View(m.list)
+	m.list.DecreaseHeight(lipgloss.Height(helpView))
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		style.Top.Render(m.list.View()),
+		m.list.Viewport.View(),
+		style.Bot.Render(helpView),
+	)
+}
+
+func (m Leaks) detect() tea.Msg {
+	return detected{hits: leakage.Detect(m.left.Data, m.right.Data, m.opts)}
+}
+
+func (m Leaks) open(
+	f File,
+	l leakage.Location,
+	itemType validation.ItemType,
+) (tea.Model, tea.Cmd) {
+	if !exists(f.Data, l.Path, itemType) {
+		return m, m.list.NewStatus(style.Error.Render(errGone.Error()))
+	}
+
+	a := f.Data.At(l.Path.To(validation.Article))
+
+	// Models are opened without parents to return here on esc
+	if itemType == validation.Paragraph {
+		model := article.New(a, f.Dataset, nil)
+		model.List.Select(l.Path.To(validation.Paragraph))
+		return m, bubblon.Open(model)
+	}
+
+	p := a.At(l.Path.To(validation.Paragraph))
+	model := paragraph.New(p, a.Title(), f.Dataset, nil)
+	model.List.Select(l.Path.To(validation.Question))
+	return m, bubblon.Open(model)
+}
+
+func (m *Leaks) updateContent() {
+	if !m.list.ItemSelected() {
+		m.list.Viewport.SetContent("")
+		return
+	}
+
+	hit := m.hits[m.list.GlobalIndex()]
+
+	content := hit.Right.Text
+	if hit.Kind == leakage.NearContext {
+		content = diff.Inline(text.Diff(hit.Left.Text, hit.Right.Text))
+	}
+
+	m.list.Viewport.SetContent(strings.Join([]string{
+		style.Highlight.Render(m.left.Name) + " " + hit.Left.String(),
+		style.Highlight.Render(m.right.Name) + " " + hit.Right.String(),
+		"",
+		content,
+	}, "\n"))
+}
+
+func exists(s *squad.SQuAD, path validation.Path, itemType validation.ItemType) bool {
+	i := path.To(validation.Article)
+	if i >= len(s.Articles) {
+		return false
+	}
+
+	j := path.To(validation.Paragraph)
+	if j >= len(s.Articles[i].Paragraphs) {
+		return false
+	}
+
+	if itemType == validation.Question {
+		return path.To(validation.Question) < len(s.Articles[i].Paragraphs[j].QAs)
+	}
+
+	return true
+}
@@ -0,0 +1,182 @@
+package lockfile
+
+import (
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+)
+
+// Owner is the process holding the lock.
+type Owner struct {
+	PID   int       `json:"pid"`
+	Host  string    `json:"host"`
+	User  string    `json:"user"`
+	Since time.Time `json:"since"`
+}
+
+func (o Owner) String() string {
+	return fmt.Sprintf("%s@%s (pid %d) since %s", o.User, o.Host, o.PID, o.Since.Format(time.DateTime))
+}
+
+// Held is the error of a lock taken by another live process.
+type Held struct {
+	Owner Owner
+}
+
+func (h Held) Error() string {
+	return "locked by " + h.Owner.String()
+}
+
+// Lock is advisory, it only keeps out other processes that ask for it.
+type Lock struct {
+	path  string
+	owner Owner
+}
+
+func Path(filename string) string {
+	return filename + ".lock"
+}
+
+// brokenAge is how old a lock that can't be read is taken over. Locks
+// are linked in place whole, so one is only left broken by a crash of
+// the disk or another tool.
+const brokenAge = 10 * time.Second
+
+// Acquire locks filename for the current process. A lock left behind
+// by a process that is no longer running on this host is taken over,
+// as is a broken one that is old enough.
+func Acquire(filename string) (*Lock, error) {
+	owner, err := current()
+	if err != nil {
+		return nil, err
+	}
+
+	path := Path(filename)
+	err = create(path, owner)
+	if !errors.Is(err, fs.ErrExist) {
+		if err != nil {
+			return nil, err
+		}
+		return &Lock{path: path, owner: owner}, nil
+	}
+
+	holder, err := read(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		// Released in the meantime
+	case err != nil:
+		if !broken(path) {
+			return nil, err
+		}
+	case !holder.stale(owner.Host):
+		return nil, Held{Owner: holder}
+	}
+
+	if err = os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	// Another process may take over the stale lock first
+	if err = create(path, owner); errors.Is(err, fs.ErrExist) {
+		holder, err = read(path)
+		if err != nil {
+			return nil, err
+		}
+		return nil, Held{Owner: holder}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Lock{path: path, owner: owner}, nil
+}
+
+// Release removes the lock unless someone else has taken it over.
+func (l *Lock) Release() error {
+	holder, err := read(l.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if holder.PID != l.owner.PID || holder.Host != l.owner.Host {
+		return nil
+	}
+
+	return os.Remove(l.path)
+}
+
+func (o Owner) stale(host string) bool {
+	return o.Host == host && !alive(o.PID)
+}
+
+func current() (Owner, error) {
+	host, err := os.Hostname()
+	if err != nil {
+		return Owner{}, err
+	}
+
+	return Owner{
+		PID:   os.Getpid(),
+		Host:  host,
+		User:  username(),
+		Since: time.Now().Truncate(time.Second),
+	}, nil
+}
+
+func username() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// create writes the owner to a temporary file and links it to path, so
+// the lock is never seen half written and fails with fs.ErrExist when
+// it's taken.
+func create(path string, owner Owner) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if err = json.MarshalWrite(file, owner); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	if err = file.Close(); err != nil {
+		return err
+	}
+
+	return os.Link(file.Name(), path)
+}
+
+// broken tells whether the lock at path that can't be read is old
+// enough not to be in the middle of being written.
+func broken(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && time.Since(info.ModTime()) > brokenAge
+}
+
+func read(path string) (Owner, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Owner{}, err
+	}
+	defer file.Close()
+
+	var owner Owner
+	if err = json.UnmarshalRead(file, &owner); err != nil {
+		return Owner{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return owner, nil
+}
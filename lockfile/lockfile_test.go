@@ -0,0 +1,88 @@
+package lockfile_test
+
+import (
+	"encoding/json/v2"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/donderom/sqwat/lockfile"
+)
+
+func TestAcquire(t *testing.T) {
+	t.Parallel()
+
+	filename := filepath.Join(t.TempDir(), "train.json")
+	lock, err := lockfile.Acquire(filename)
+	require.NoError(t, err)
+	assert.FileExists(t, lockfile.Path(filename))
+
+	_, err = lockfile.Acquire(filename)
+	held, ok := errors.AsType[lockfile.Held](err)
+	require.True(t, ok)
+	assert.Equal(t, os.Getpid(), held.Owner.PID)
+	assert.Contains(t, err.Error(), "locked by")
+
+	require.NoError(t, lock.Release())
+	assert.NoFileExists(t, lockfile.Path(filename))
+
+	lock, err = lockfile.Acquire(filename)
+	require.NoError(t, err)
+	require.NoError(t, lock.Release())
+}
+
+func TestAcquireStale(t *testing.T) {
+	t.Parallel()
+
+	cmd := exec.Command("true")
+	require.NoError(t, cmd.Run())
+
+	host, err := os.Hostname()
+	require.NoError(t, err)
+
+	filename := filepath.Join(t.TempDir(), "train.json")
+	stale, err := json.Marshal(lockfile.Owner{PID: cmd.Process.Pid, Host: host, User: "gone"})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(lockfile.Path(filename), stale, 0o644))
+
+	lock, err := lockfile.Acquire(filename)
+	require.NoError(t, err)
+	require.NoError(t, lock.Release())
+}
+
+func TestAcquireOtherHost(t *testing.T) {
+	t.Parallel()
+
+	filename := filepath.Join(t.TempDir(), "train.json")
+	other, err := json.Marshal(lockfile.Owner{PID: 1, Host: "elsewhere", User: "someone"})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(lockfile.Path(filename), other, 0o644))
+
+	_, err = lockfile.Acquire(filename)
+	assert.Equal(t, lockfile.Held{Owner: lockfile.Owner{PID: 1, Host: "elsewhere", User: "someone"}}, err)
+}
+
+func TestAcquireBroken(t *testing.T) {
+	t.Parallel()
+
+	filename := filepath.Join(t.TempDir(), "train.json")
+	path := lockfile.Path(filename)
+	require.NoError(t, os.WriteFile(path, nil, 0o644))
+
+	_, err := lockfile.Acquire(filename)
+	require.Error(t, err)
+
+	old := time.Now().Add(-time.Minute)
+	require.NoError(t, os.Chtimes(path, old, old))
+
+	lock, err := lockfile.Acquire(filename)
+	require.NoError(t, err)
+	require.NoError(t, lock.Release())
+	assert.NoFileExists(t, path)
+}
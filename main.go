@@ -1,19 +1,32 @@
 package main
 
 import (
+	"bufio"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/donderom/sqwat/cli"
 	"github.com/donderom/sqwat/splash"
+	"github.com/donderom/sqwat/teax"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/donderom/bubblon"
 )
 
 func main() {
-	model, err := model()
+	session := splash.NewSession()
+	model, stdin, err := model(session)
 	if err != nil {
-		fail(err)
+		// Locks taken on the way are released
+		fail(errors.Join(err, session.Close()))
+	}
+
+	// Headless commands are done by now
+	if model == nil {
+		return
 	}
 
 	controller, err := bubblon.New(model)
@@ -21,8 +34,16 @@ func main() {
 		fail(err)
 	}
 
-	p := tea.NewProgram(controller, tea.WithAltScreen())
+	options, err := programOptions()
+	if err != nil {
+		fail(err)
+	}
+
+	p := tea.NewProgram(controller, options...)
 	m, err := p.Run()
+	// Pending edits are saved and other sessions may edit the file
+	// whatever the outcome
+	err = errors.Join(err, warned(session.Close()))
 	if err != nil {
 		fail(err)
 	}
@@ -30,28 +51,157 @@ func main() {
 	if m, ok := m.(bubblon.Controller); ok && m.Err != nil {
 		fail(m.Err)
 	}
+
+	if stdin != nil && stdin.Changed() {
+		if err = warned(flush(stdin)); err != nil {
+			fail(err)
+		}
+	}
 }
 
-func model() (tea.Model, error) {
-	if len(os.Args) < 2 {
-		return splash.NewPicker("."), nil
+func model(session *splash.Session) (tea.Model, *splash.Stdin, error) {
+	if len(os.Args) > 1 {
+		if command, ok := cli.Lookup(os.Args[1]); ok {
+			model, err := command.Exec(session, os.Args[2:])
+			return model, nil, err
+		}
+	}
+
+	flags := flag.NewFlagSet("sqwat", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: sqwat [flags] [dataset.json | directory | -]")
+		flags.PrintDefaults()
+	}
+	readOnly := flags.Bool("readonly", false, "browse without saving any changes")
+	output := flags.String("output", "", "save target of data read from stdin, - for stdout (asked at quit by default)")
+	saveDelay := flags.Duration("save-delay", splash.DefaultSaveDelay, "how long edits wait for others to be saved together")
+	manualSave := flags.Bool("manual-save", false, "keep edits in memory until saved with ctrl+s")
+	user := flags.String("user", "", "name to record in the audit log (default $USER)")
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		return nil, nil, helpless(err)
+	}
+
+	configure := func(m splash.Splash) splash.Splash {
+		if *readOnly {
+			m = m.ReadOnly()
+		}
+		if *manualSave {
+			m = m.ManualSave()
+		}
+		if *user != "" {
+			m = m.User(*user)
+		}
+		return m.SaveDelay(*saveDelay)
+	}
+
+	if flags.NArg() == 0 {
+		return splash.NewPicker(session, ".", configure), nil, nil
+	}
+
+	path := flags.Arg(0)
+	// Flags may follow the path as well
+	if err := flags.Parse(flags.Args()[1:]); err != nil {
+		return nil, nil, helpless(err)
 	}
 
-	path := os.Args[1]
+	if path == cli.Stdio {
+		if *manualSave && (*output == "" || *output == cli.Stdio) {
+			return nil, nil, errors.New("--manual-save needs an --output file, data from stdin is kept in memory until quit")
+		}
+		model, stdin := splash.NewStdin(session, *output)
+		return configure(model), stdin, nil
+	}
 
 	fileInfo, err := os.Stat(path)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if fileInfo.IsDir() {
-		return splash.NewPicker(path), nil
+		return splash.NewPicker(session, path, configure), nil, nil
+	}
+
+	return configure(splash.New(session, path)), nil, nil
+}
+
+// programOptions keep the TUI on the terminal when stdin or stdout are
+// part of a pipeline.
+func programOptions() ([]tea.ProgramOption, error) {
+	options := []tea.ProgramOption{tea.WithAltScreen()}
+
+	if !isTerminal(os.Stdin) {
+		options = append(options, tea.WithInputTTY())
 	}
 
-	return splash.New(path), nil
+	if !isTerminal(os.Stdout) {
+		tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, tea.WithOutput(tty))
+	}
+
+	return options, nil
+}
+
+// flush writes the changes to data from stdin to the output or the file
+// asked for on the terminal.
+func flush(stdin *splash.Stdin) error {
+	if stdin.Output() != "" {
+		return stdin.WriteTo(stdin.Output())
+	}
+
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer tty.Close()
+
+	fmt.Fprint(tty, "Save changes to (- for stdout, empty to discard): ")
+	path, err := bufio.NewReader(tty).ReadString('\n')
+	if err != nil {
+		return err
+	}
+
+	if path = strings.TrimSpace(path); path == "" {
+		return nil
+	}
+
+	return stdin.WriteTo(path)
+}
+
+// warned reports the warnings of saves that went through on stderr and
+// keeps the errors.
+func warned(err error) error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var rest error
+		for _, e := range joined.Unwrap() {
+			rest = errors.Join(rest, warned(e))
+		}
+		return rest
+	}
+
+	if teax.IsWarning(err) {
+		fmt.Fprintln(os.Stderr, "Warning:", err)
+		return nil
+	}
+	return err
+}
+
+// helpless is no error for the help the flags have printed.
+func helpless(err error) error {
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	return err
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
 }
 
 func fail(err error) {
-	fmt.Println("Error running program:", err)
+	fmt.Fprintln(os.Stderr, "Error running program:", err)
 	os.Exit(1)
 }
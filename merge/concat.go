@@ -0,0 +1,137 @@
+package merge
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/donderom/sqwat/squad"
+)
+
+type IDPolicy uint8
+
+const (
+	RejectIDs IDPolicy = iota
+	RegenerateIDs
+)
+
+type NoteKind uint8
+
+const (
+	MergedArticle NoteKind = iota
+	CollapsedParagraph
+	DroppedDuplicate
+	RenamedID
+	CollidingID
+)
+
+func (k NoteKind) String() string {
+	switch k {
+	case MergedArticle:
+		return "merged articles"
+	case CollapsedParagraph:
+		return "collapsed paragraphs"
+	case DroppedDuplicate:
+		return "dropped duplicate questions"
+	case RenamedID:
+		return "regenerated IDs"
+	case CollidingID:
+		return "colliding IDs"
+	}
+	return "unknown"
+}
+
+// Note records a single decision taken while concatenating.
+type Note struct {
+	Kind    NoteKind
+	Message string
+}
+
+var ErrIDCollision = errors.New("colliding question IDs")
+
+// Concat merges articles with the same title, collapses paragraphs with
+// identical contexts and unions their questions. Different questions
+// sharing an ID are either rejected or get a new ID depending on policy.
+func Concat(sets []*squad.SQuAD, names []string, policy IDPolicy) (*squad.SQuAD, []Note, error) {
+	var notes []Note
+	note := func(kind NoteKind, format string, args ...any) {
+		notes = append(notes, Note{Kind: kind, Message: fmt.Sprintf(format, args...)})
+	}
+
+	result := &squad.SQuAD{}
+	articles := make(map[string]int)
+	ids := make(map[string]struct{})
+	var collisions []string
+
+	for n, s := range sets {
+		source := names[n]
+
+		if result.Version == "" {
+			result.Version = s.Version
+		}
+
+		for _, article := range s.Articles {
+			index, ok := articles[article.Name]
+			if !ok {
+				index = len(result.Articles)
+				articles[article.Name] = index
+				result.Add(squad.Article{Name: article.Name})
+			} else {
+				note(MergedArticle, "%q from %s", article.Name, source)
+			}
+			target := result.At(index)
+
+			for _, paragraph := range article.Paragraphs {
+				p := slices.IndexFunc(target.Paragraphs, func(other squad.Paragraph) bool {
+					return other.Context == paragraph.Context
+				})
+				if p == -1 {
+					target.Add(squad.Paragraph{Context: paragraph.Context})
+					p = len(target.Paragraphs) - 1
+				} else {
+					note(CollapsedParagraph, "%q in %q from %s",
+						abbrev(paragraph.Context), article.Name, source)
+				}
+				para := target.At(p)
+
+				for _, qa := range paragraph.QAs {
+					if slices.ContainsFunc(para.QAs, qa.Equal) {
+						note(DroppedDuplicate, "%q (%s) from %s", qa.Question, qa.Id, source)
+						continue
+					}
+
+					if _, ok := ids[qa.Id]; ok {
+						if policy == RejectIDs {
+							note(CollidingID, "%s (%q) from %s", qa.Id, qa.Question, source)
+							collisions = append(collisions, qa.Id)
+							continue
+						}
+
+						old := qa.Id
+						qa.GenerateID()
+						note(RenamedID, "%s → %s (%q) from %s", old, qa.Id, qa.Question, source)
+					}
+
+					ids[qa.Id] = struct{}{}
+					para.Add(qa)
+				}
+			}
+		}
+	}
+
+	if len(collisions) > 0 {
+		return nil, notes, fmt.Errorf("%w: %s", ErrIDCollision, strings.Join(collisions, ", "))
+	}
+
+	return result, notes, nil
+}
+
+func abbrev(s string) string {
+	const maxLen = 40
+	runes := []rune(s)
+	if len(runes) <= maxLen {
+		return s
+	}
+	return string(runes[:maxLen]) + "…"
+}
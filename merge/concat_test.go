@@ -0,0 +1,75 @@
+package merge_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/donderom/sqwat/merge"
+	"github.com/donderom/sqwat/squad"
+)
+
+func TestConcat(t *testing.T) {
+	t.Parallel()
+
+	names := []string{"a.json", "b.json"}
+
+	t.Run("collapse", func(t *testing.T) {
+		t.Parallel()
+
+		other := data()
+		other.At(0).At(0).Add(squad.QA{Id: "3", Question: "Who made Go?"})
+		other.Add(squad.Article{Name: "Rust"})
+
+		result, notes, err := merge.Concat([]*squad.SQuAD{data(), other}, names, merge.RejectIDs)
+		require.NoError(t, err)
+
+		require.Len(t, result.Articles, 2)
+		require.Len(t, result.Articles[0].Paragraphs, 1)
+		assert.Len(t, result.Articles[0].Paragraphs[0].QAs, 3)
+		assert.Equal(t, "v2.0", result.Version)
+
+		assert.Equal(t, []merge.NoteKind{
+			merge.MergedArticle,
+			merge.CollapsedParagraph,
+			merge.DroppedDuplicate,
+			merge.DroppedDuplicate,
+		}, kinds(notes))
+	})
+
+	t.Run("reject colliding IDs", func(t *testing.T) {
+		t.Parallel()
+
+		other := data()
+		other.At(0).At(0).At(0).Question = "What was Go?"
+
+		_, notes, err := merge.Concat([]*squad.SQuAD{data(), other}, names, merge.RejectIDs)
+		require.ErrorIs(t, err, merge.ErrIDCollision)
+		assert.Contains(t, kinds(notes), merge.CollidingID)
+	})
+
+	t.Run("regenerate colliding IDs", func(t *testing.T) {
+		t.Parallel()
+
+		other := data()
+		other.At(0).At(0).At(0).Question = "What was Go?"
+
+		result, notes, err := merge.Concat([]*squad.SQuAD{data(), other}, names, merge.RegenerateIDs)
+		require.NoError(t, err)
+		assert.Contains(t, kinds(notes), merge.RenamedID)
+
+		qas := result.Articles[0].Paragraphs[0].QAs
+		require.Len(t, qas, 3)
+		assert.Equal(t, "What was Go?", qas[2].Question)
+		assert.NotEqual(t, "1", qas[2].Id)
+	})
+}
+
+func kinds(notes []merge.Note) []merge.NoteKind {
+	result := make([]merge.NoteKind, len(notes))
+	for i, n := range notes {
+		result[i] = n.Kind
+	}
+	return result
+}
@@ -0,0 +1,404 @@
+package merge
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+
+	"github.com/donderom/sqwat/diff"
+	"github.com/donderom/sqwat/squad"
+	"github.com/donderom/sqwat/validation"
+)
+
+type Side uint8
+
+const (
+	Unresolved Side = iota
+	Ours
+	Theirs
+)
+
+func (s Side) String() string {
+	switch s {
+	case Ours:
+		return "ours"
+	case Theirs:
+		return "theirs"
+	}
+	return "unresolved"
+}
+
+// Resolutions maps conflict keys to the picked side.
+// Unresolved conflicts fall back to ours.
+type Resolutions map[string]Side
+
+const (
+	deleted = "(deleted)"
+	none    = "(none)"
+)
+
+type Conflict struct {
+	Key    string
+	Type   validation.ItemType
+	Path   validation.Path
+	Where  string
+	Field  string
+	Base   string
+	Ours   string
+	Theirs string
+}
+
+var _ list.DefaultItem = Conflict{}
+
+func (c Conflict) Title() string       { return c.Field + " " + c.Where }
+func (c Conflict) Description() string { return c.Type.String() }
+func (c Conflict) FilterValue() string { return c.Title() }
+
+type Result struct {
+	Data      *squad.SQuAD
+	Conflicts []Conflict
+}
+
+// Merge applies the changes made in ours and theirs since base.
+// Articles are matched by title, paragraphs by context similarity,
+// questions by ID and answers by their position.
+func Merge(base, ours, theirs *squad.SQuAD, resolutions Resolutions) Result {
+	m := merger{resolutions: resolutions}
+	root := scope{key: "", where: "in dataset", itemType: validation.Article}
+
+	data := &squad.SQuAD{
+		Version: scalar(&m, root, "version", base.Version, ours.Version, theirs.Version),
+		Articles: mergeItems(&m, root, articles,
+			base.Articles, ours.Articles, theirs.Articles),
+	}
+
+	return Result{Data: data, Conflicts: m.conflicts}
+}
+
+type merger struct {
+	resolutions Resolutions
+	conflicts   []Conflict
+}
+
+func (m *merger) resolve(c Conflict) Side {
+	m.conflicts = append(m.conflicts, c)
+	if m.resolutions[c.Key] == Theirs {
+		return Theirs
+	}
+	return Ours
+}
+
+type scope struct {
+	key      string
+	where    string
+	path     validation.Path
+	itemType validation.ItemType
+}
+
+func (s scope) child(l levelInfo, id string, index int, title string) scope {
+	path := maps.Clone(s.path)
+	if path == nil {
+		path = make(validation.Path, 1)
+	}
+	path[l.itemType] = index
+
+	where := fmt.Sprintf("%s, %s %d", s.where, l.name, index+1)
+	if l.itemType == validation.Article {
+		where = "in " + strconv.Quote(title)
+	}
+
+	return scope{
+		key:      s.key + "/" + l.name + ":" + id,
+		where:    where,
+		path:     path,
+		itemType: l.itemType,
+	}
+}
+
+func (s scope) conflict(field, base, ours, theirs string) Conflict {
+	return Conflict{
+		Key:    s.key + "/" + field,
+		Type:   s.itemType,
+		Path:   s.path,
+		Where:  s.where,
+		Field:  field,
+		Base:   base,
+		Ours:   ours,
+		Theirs: theirs,
+	}
+}
+
+type levelInfo struct {
+	name     string
+	itemType validation.ItemType
+}
+
+type level[T list.DefaultItem] struct {
+	levelInfo
+	match func(old, new []T) []diff.Pair
+	equal func(a, b T) bool
+	merge func(m *merger, s scope, base, ours, theirs T) T
+}
+
+var (
+	articles = level[squad.Article]{
+		levelInfo: levelInfo{name: "article", itemType: validation.Article},
+		match:     diff.MatchArticles,
+		equal:     squad.Article.Equal,
+		merge:     mergeArticle,
+	}
+
+	paragraphs = level[squad.Paragraph]{
+		levelInfo: levelInfo{name: "paragraph", itemType: validation.Paragraph},
+		match:     diff.MatchParagraphs,
+		equal:     squad.Paragraph.Equal,
+		merge:     mergeParagraph,
+	}
+
+	qas = level[squad.QA]{
+		levelInfo: levelInfo{name: "question", itemType: validation.Question},
+		match:     diff.MatchQAs,
+		equal:     squad.QA.Equal,
+		merge:     mergeQA,
+	}
+)
+
+func mergeArticle(m *merger, s scope, base, ours, theirs squad.Article) squad.Article {
+	return squad.Article{
+		Name: ours.Name,
+		Paragraphs: mergeItems(m, s, paragraphs,
+			base.Paragraphs, ours.Paragraphs, theirs.Paragraphs),
+	}
+}
+
+func mergeParagraph(m *merger, s scope, base, ours, theirs squad.Paragraph) squad.Paragraph {
+	return squad.Paragraph{
+		Context: scalar(m, s, "context", base.Context, ours.Context, theirs.Context),
+		QAs:     mergeItems(m, s, qas, base.QAs, ours.QAs, theirs.QAs),
+	}
+}
+
+func mergeQA(m *merger, s scope, base, ours, theirs squad.QA) squad.QA {
+	impossible := scalar(m, s, "is_impossible",
+		strconv.FormatBool(base.Impossible),
+		strconv.FormatBool(ours.Impossible),
+		strconv.FormatBool(theirs.Impossible),
+	)
+
+	qa := squad.QA{
+		Id:         ours.Id,
+		Question:   scalar(m, s, "question", base.Question, ours.Question, theirs.Question),
+		Impossible: impossible == "true",
+		CorrectAnswers: mergeAnswers(m, s, "answers",
+			base.CorrectAnswers, ours.CorrectAnswers, theirs.CorrectAnswers),
+		PlausibleAnswers: mergeAnswers(m, s, "plausible_answers",
+			base.PlausibleAnswers, ours.PlausibleAnswers, theirs.PlausibleAnswers),
+	}
+
+	// One side made the question impossible while the other answered it
+	if qa.Impossible && len(qa.CorrectAnswers) > 0 {
+		c := s.conflict("is_impossible", answerable(base), answerable(ours), answerable(theirs))
+		side := ours
+		if m.resolve(c) == Theirs {
+			side = theirs
+		}
+		qa.Impossible, qa.CorrectAnswers = side.Impossible, side.CorrectAnswers
+	}
+
+	return qa
+}
+
+func answerable(qa squad.QA) string {
+	if qa.Impossible {
+		return "impossible"
+	}
+	return fmt.Sprintf("%d answers", len(qa.CorrectAnswers))
+}
+
+// mergeAnswers matches the answers by their text and start. Answers
+// deleted on either side are gone and the ones added on either side
+// are kept, both sides replacing an answer of base differently is a
+// conflict over the whole list.
+func mergeAnswers(m *merger, s scope, field string, base, ours, theirs []squad.Answer) []squad.Answer {
+	inBase, inOurs, inTheirs := answerSet(base), answerSet(ours), answerSet(theirs)
+
+	replaced := slices.ContainsFunc(base, func(a squad.Answer) bool {
+		return !inOurs[a] && !inTheirs[a]
+	})
+	if replaced && !maps.Equal(added(ours, inBase), added(theirs, inBase)) {
+		c := s.conflict(field, answerList(base), answerList(ours), answerList(theirs))
+		if m.resolve(c) == Theirs {
+			return theirs
+		}
+		return ours
+	}
+
+	var result []squad.Answer
+	for _, a := range ours {
+		if !inBase[a] || inTheirs[a] {
+			result = append(result, a)
+		}
+	}
+	for _, a := range theirs {
+		if !inBase[a] && !inOurs[a] {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+func answerSet(answers []squad.Answer) map[squad.Answer]bool {
+	set := make(map[squad.Answer]bool, len(answers))
+	for _, a := range answers {
+		set[a] = true
+	}
+	return set
+}
+
+// added keeps the answers that are not in base.
+func added(answers []squad.Answer, base map[squad.Answer]bool) map[squad.Answer]bool {
+	set := make(map[squad.Answer]bool)
+	for _, a := range answers {
+		if !base[a] {
+			set[a] = true
+		}
+	}
+	return set
+}
+
+func answerList(answers []squad.Answer) string {
+	if len(answers) == 0 {
+		return none
+	}
+
+	list := make([]string, len(answers))
+	for i, a := range answers {
+		list[i] = fmt.Sprintf("%q at %d", a.Text, a.Start)
+	}
+	return strings.Join(list, ", ")
+}
+
+func scalar(m *merger, s scope, field, base, ours, theirs string) string {
+	switch {
+	case ours == theirs:
+		return ours
+	case ours == base:
+		return theirs
+	case theirs == base:
+		return ours
+	}
+
+	if m.resolve(s.conflict(field, base, ours, theirs)) == Theirs {
+		return theirs
+	}
+	return ours
+}
+
+func mergeItems[T list.DefaultItem](
+	m *merger,
+	s scope,
+	l level[T],
+	base, ours, theirs []T,
+) []T {
+	oursBase, baseOurs, oursAdded := index(l.match(base, ours))
+	_, baseTheirs, theirsAdded := index(l.match(base, theirs))
+
+	// Items added on both sides with the same identity
+	both := make(map[int]int)
+	usedTheirs := make(map[int]struct{})
+	for _, p := range l.match(pick(ours, oursAdded), pick(theirs, theirsAdded)) {
+		if !p.Added() && !p.Removed() {
+			both[oursAdded[p.Old]] = theirsAdded[p.New]
+			usedTheirs[theirsAdded[p.New]] = struct{}{}
+		}
+	}
+
+	var result []T
+
+	for i, o := range ours {
+		index := len(result)
+
+		if b, ok := oursBase[i]; ok {
+			child := s.child(l.levelInfo, "b"+strconv.Itoa(b), index, o.Title())
+			if j, ok := baseTheirs[b]; ok {
+				result = append(result, l.merge(m, child, base[b], o, theirs[j]))
+				continue
+			}
+
+			// Deleted by them
+			if l.equal(base[b], o) {
+				continue
+			}
+
+			c := child.conflict(l.name, base[b].Title(), o.Title(), deleted)
+			if m.resolve(c) == Ours {
+				result = append(result, o)
+			}
+			continue
+		}
+
+		child := s.child(l.levelInfo, "o"+strconv.Itoa(i), index, o.Title())
+		if j, ok := both[i]; ok {
+			result = append(result, l.merge(m, child, *new(T), o, theirs[j]))
+			continue
+		}
+
+		result = append(result, o)
+	}
+
+	for b := range base {
+		if _, ok := baseOurs[b]; ok {
+			continue
+		}
+
+		// Deleted by us
+		j, ok := baseTheirs[b]
+		if !ok || l.equal(base[b], theirs[j]) {
+			continue
+		}
+
+		t := theirs[j]
+		child := s.child(l.levelInfo, "b"+strconv.Itoa(b), len(result), t.Title())
+		c := child.conflict(l.name, base[b].Title(), deleted, t.Title())
+		if m.resolve(c) == Theirs {
+			result = append(result, t)
+		}
+	}
+
+	for _, j := range theirsAdded {
+		if _, ok := usedTheirs[j]; !ok {
+			result = append(result, theirs[j])
+		}
+	}
+
+	return result
+}
+
+func index(pairs []diff.Pair) (newOld, oldNew map[int]int, added []int) {
+	newOld = make(map[int]int, len(pairs))
+	oldNew = make(map[int]int, len(pairs))
+
+	for _, p := range pairs {
+		switch {
+		case p.Added():
+			added = append(added, p.New)
+		case !p.Removed():
+			newOld[p.New] = p.Old
+			oldNew[p.Old] = p.New
+		}
+	}
+
+	return newOld, oldNew, added
+}
+
+func pick[T any](items []T, indices []int) []T {
+	result := make([]T, len(indices))
+	for i, index := range indices {
+		result[i] = items[index]
+	}
+	return result
+}
@@ -0,0 +1,232 @@
+package merge_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/donderom/sqwat/merge"
+	"github.com/donderom/sqwat/squad"
+)
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no changes", func(t *testing.T) {
+		t.Parallel()
+
+		result := merge.Merge(data(), data(), data(), nil)
+		assert.Empty(t, result.Conflicts)
+		assert.True(t, data().Articles[0].Equal(result.Data.Articles[0]))
+	})
+
+	t.Run("non-conflicting changes", func(t *testing.T) {
+		t.Parallel()
+
+		ours := data()
+		ours.At(0).At(0).At(0).Question = "What was Go?"
+		ours.At(0).At(0).At(1).Add(squad.Answer{Text: "statically typed", Start: 8})
+
+		theirs := data()
+		theirs.At(0).At(0).Add(squad.QA{Id: "3", Question: "Who made Go?"})
+		theirs.At(0).At(0).At(0).CorrectAnswers[0].Start = 7
+		theirs.Add(squad.Article{Name: "Rust"})
+
+		result := merge.Merge(data(), ours, theirs, nil)
+		require.Empty(t, result.Conflicts)
+
+		merged := result.Data
+		require.Len(t, merged.Articles, 2)
+		assert.Equal(t, "Rust", merged.Articles[1].Name)
+
+		qas := merged.Articles[0].Paragraphs[0].QAs
+		require.Len(t, qas, 3)
+		assert.Equal(t, "What was Go?", qas[0].Question)
+		assert.Equal(t, []squad.Answer{{Text: "a statically typed language", Start: 7}}, qas[0].Answers())
+		assert.Len(t, qas[1].Answers(), 1)
+		assert.Equal(t, "3", qas[2].Id)
+	})
+
+	t.Run("deleted on one side", func(t *testing.T) {
+		t.Parallel()
+
+		ours := data()
+		ours.At(0).At(0).Remove(1)
+
+		result := merge.Merge(data(), ours, data(), nil)
+		require.Empty(t, result.Conflicts)
+		assert.Len(t, result.Data.Articles[0].Paragraphs[0].QAs, 1)
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		t.Parallel()
+
+		ours := data()
+		ours.At(0).At(0).At(0).Question = "What was Go?"
+		ours.At(0).At(0).At(1).Question = "Is Go dynamically typed?"
+
+		theirs := data()
+		theirs.At(0).At(0).At(0).Question = "What is Go exactly?"
+		theirs.At(0).At(0).Remove(1)
+
+		result := merge.Merge(data(), ours, theirs, nil)
+		require.Len(t, result.Conflicts, 2)
+
+		question := result.Conflicts[0]
+		assert.Equal(t, "question", question.Field)
+		assert.Equal(t, "What is Go?", question.Base)
+		assert.Equal(t, "What was Go?", question.Ours)
+		assert.Equal(t, "What is Go exactly?", question.Theirs)
+
+		// Unresolved conflicts fall back to ours
+		qas := result.Data.Articles[0].Paragraphs[0].QAs
+		require.Len(t, qas, 2)
+		assert.Equal(t, "What was Go?", qas[0].Question)
+
+		resolutions := merge.Resolutions{}
+		for _, c := range result.Conflicts {
+			resolutions[c.Key] = merge.Theirs
+		}
+
+		result = merge.Merge(data(), ours, theirs, resolutions)
+		assert.Len(t, result.Conflicts, 2)
+		qas = result.Data.Articles[0].Paragraphs[0].QAs
+		require.Len(t, qas, 1)
+		assert.Equal(t, "What is Go exactly?", qas[0].Question)
+	})
+}
+
+func TestMergeAnswers(t *testing.T) {
+	t.Parallel()
+
+	t.Run("same edit", func(t *testing.T) {
+		t.Parallel()
+
+		ours, theirs := data(), data()
+		ours.At(0).At(0).At(0).CorrectAnswers[0].Start = 7
+		theirs.At(0).At(0).At(0).CorrectAnswers[0].Start = 7
+
+		result := merge.Merge(data(), ours, theirs, nil)
+		require.Empty(t, result.Conflicts)
+		assert.Equal(t, 7, result.Data.Articles[0].Paragraphs[0].QAs[0].CorrectAnswers[0].Start)
+	})
+
+	t.Run("concurrent edits", func(t *testing.T) {
+		t.Parallel()
+
+		ours, theirs := data(), data()
+		ours.At(0).At(0).At(0).CorrectAnswers[0] = squad.Answer{Text: "statically typed", Start: 8}
+		theirs.At(0).At(0).At(0).CorrectAnswers[0] = squad.Answer{Text: "language", Start: 25}
+
+		result := merge.Merge(data(), ours, theirs, nil)
+		require.Len(t, result.Conflicts, 1)
+
+		c := result.Conflicts[0]
+		assert.Equal(t, "answers", c.Field)
+		assert.Equal(t, `"a statically typed language" at 6`, c.Base)
+		assert.Equal(t, `"statically typed" at 8`, c.Ours)
+		assert.Equal(t, `"language" at 25`, c.Theirs)
+		assert.Equal(t, []squad.Answer{{Text: "statically typed", Start: 8}},
+			result.Data.Articles[0].Paragraphs[0].QAs[0].CorrectAnswers)
+
+		result = merge.Merge(data(), ours, theirs, merge.Resolutions{c.Key: merge.Theirs})
+		assert.Equal(t, []squad.Answer{{Text: "language", Start: 25}},
+			result.Data.Articles[0].Paragraphs[0].QAs[0].CorrectAnswers)
+	})
+
+	t.Run("deleted on both sides", func(t *testing.T) {
+		t.Parallel()
+
+		base := data()
+		base.At(0).At(0).At(0).Add(squad.Answer{Text: "language", Start: 25})
+		ours, theirs := base.Clone(), base.Clone()
+		ours.At(0).At(0).At(0).CorrectAnswers = []squad.Answer{{Text: "language", Start: 25}}
+		theirs.At(0).At(0).At(0).CorrectAnswers = []squad.Answer{{Text: "a statically typed language", Start: 6}}
+
+		result := merge.Merge(base, ours, theirs, nil)
+		require.Empty(t, result.Conflicts)
+		assert.Empty(t, result.Data.Articles[0].Paragraphs[0].QAs[0].CorrectAnswers)
+	})
+
+	t.Run("added on both sides", func(t *testing.T) {
+		t.Parallel()
+
+		ours, theirs := data(), data()
+		ours.At(0).At(0).At(0).Add(squad.Answer{Text: "statically typed", Start: 8})
+		theirs.At(0).At(0).At(0).Add(squad.Answer{Text: "language", Start: 25})
+
+		result := merge.Merge(data(), ours, theirs, nil)
+		require.Empty(t, result.Conflicts)
+		assert.Equal(t, []squad.Answer{
+			{Text: "a statically typed language", Start: 6},
+			{Text: "statically typed", Start: 8},
+			{Text: "language", Start: 25},
+		}, result.Data.Articles[0].Paragraphs[0].QAs[0].CorrectAnswers)
+	})
+
+	t.Run("edited and deleted", func(t *testing.T) {
+		t.Parallel()
+
+		ours, theirs := data(), data()
+		ours.At(0).At(0).At(0).CorrectAnswers[0].Start = 7
+		theirs.At(0).At(0).At(0).CorrectAnswers = nil
+
+		result := merge.Merge(data(), ours, theirs, nil)
+		require.Len(t, result.Conflicts, 1)
+		assert.Equal(t, "(none)", result.Conflicts[0].Theirs)
+
+		result = merge.Merge(data(), ours, theirs, merge.Resolutions{result.Conflicts[0].Key: merge.Theirs})
+		assert.Empty(t, result.Data.Articles[0].Paragraphs[0].QAs[0].CorrectAnswers)
+	})
+
+	t.Run("impossible and answered", func(t *testing.T) {
+		t.Parallel()
+
+		ours, theirs := data(), data()
+		ours.At(0).At(0).At(1).Impossible = true
+		theirs.At(0).At(0).At(1).Add(squad.Answer{Text: "statically typed", Start: 8})
+
+		result := merge.Merge(data(), ours, theirs, nil)
+		require.Len(t, result.Conflicts, 1)
+		assert.Equal(t, "is_impossible", result.Conflicts[0].Field)
+
+		qa := result.Data.Articles[0].Paragraphs[0].QAs[1]
+		assert.True(t, qa.Impossible)
+		assert.Empty(t, qa.CorrectAnswers)
+
+		result = merge.Merge(data(), ours, theirs, merge.Resolutions{result.Conflicts[0].Key: merge.Theirs})
+		qa = result.Data.Articles[0].Paragraphs[0].QAs[1]
+		assert.False(t, qa.Impossible)
+		assert.Len(t, qa.CorrectAnswers, 1)
+	})
+}
+
+func data() *squad.SQuAD {
+	return &squad.SQuAD{
+		Version: "v2.0",
+		Articles: []squad.Article{
+			{
+				Name: "Go",
+				Paragraphs: []squad.Paragraph{
+					{
+						Context: "Go is a statically typed language.",
+						QAs: []squad.QA{
+							{
+								Id:       "1",
+								Question: "What is Go?",
+								CorrectAnswers: []squad.Answer{
+									{Text: "a statically typed language", Start: 6},
+								},
+							},
+							{
+								Id:       "2",
+								Question: "Is Go dynamic?",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,218 @@
+package mergeview
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/donderom/sqwat/keyset"
+	"github.com/donderom/sqwat/merge"
+	"github.com/donderom/sqwat/style"
+	"github.com/donderom/sqwat/teax"
+	"github.com/donderom/sqwat/text"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/donderom/bubblon"
+)
+
+type Item struct {
+	merge.Conflict
+	Side merge.Side
+}
+
+func (i Item) Description() string {
+	return fmt.Sprintf("%s · %s", i.Type, i.Side)
+}
+
+// Done is called with the picked sides when the user saves.
+type Done func(resolutions merge.Resolutions) tea.Cmd
+
+type Resolved struct {
+	Err error
+}
+
+type Merge struct {
+	list       teax.ViewList[Item, text.Segment]
+	items      []Item
+	done       Done
+	standalone bool
+}
+
+var _ tea.Model = Merge{}
+
+var (
+	keys []key.Binding = []key.Binding{
+		keyset.Ours,
+		keyset.Theirs,
+		keyset.Save,
+		keyset.Esc,
+	}
+
+	fullKeys []key.Binding = []key.Binding{
+		keyset.Next,
+		keyset.Prev,
+	}
+
+	delegate teax.Delegate[Item] = teax.Delegate[Item]{
+		Style:           conflictStyle,
+		ItemName:        "conflict",
+		ShowDescription: true,
+		ShortHelpKeys:   keys,
+		FullHelpKeys:    slices.Concat(keys, fullKeys),
+	}
+
+	conflictStyle teax.StyleFunc[Item] = teax.StyleFunc[Item](
+		func(defaultStyles teax.Styles) teax.ItemStyles[Item] {
+			return func(item Item) teax.Styles {
+				styles := defaultStyles
+				if item.Side == merge.Unresolved {
+					border := style.Border.Error
+					styles.NormalDesc = border.Apply(styles.NormalDesc)
+					styles.SelectedDesc = border.Apply(styles.SelectedDesc)
+				}
+				return styles
+			}
+		})
+)
+
+func New(title string, conflicts []merge.Conflict, done Done) Merge {
+	items := make([]Item, len(conflicts))
+	for i, c := range conflicts {
+		items[i] = Item{Conflict: c}
+	}
+
+	return Merge{
+		list:  teax.NewViewList[text.Segment](items, title, delegate),
+		items: items,
+		done:  done,
+	}
+}
+
+// Standalone makes the screen quit the app on esc as there is
+// nothing to return to.
+func (m Merge) Standalone() Merge {
+	m.standalone = true
+	return m
+}
+
+func (m Merge) Init() tea.Cmd {
+	return nil
+}
+
+func (m Merge) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.Resize(msg)
+		m.updateContent()
+		return m, nil
+
+	case Resolved:
+		if msg.Err != nil {
+			m.list.StopSpinner()
+			return m, m.list.NewStatus(style.Error.Render(msg.Err.Error()))
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.list.Unfiltered() {
+			switch {
+			case key.Matches(msg, keyset.Ours):
+				return m.pick(merge.Ours)
+
+			case key.Matches(msg, keyset.Theirs):
+				return m.pick(merge.Theirs)
+
+			case key.Matches(msg, keyset.Save):
+				// Conflicts left alone would be saved as ours unseen
+				if left := m.unresolved(); left > 0 {
+					return m, m.list.NewStatus(style.Error.Render(
+						fmt.Sprintf("%d conflicts left, pick ours or theirs before saving", left),
+					))
+				}
+				return m, tea.Batch(m.list.StartSpinner(), m.done(m.resolutions()))
+
+			case key.Matches(msg, keyset.Esc):
+				if m.standalone {
+					return m, tea.Quit
+				}
+				return m, bubblon.Close
+
+			case key.Matches(msg, keyset.Quit):
+				return m, tea.Quit
+			}
+		}
+	}
+
+	m.list, cmd = m.list.Update(msg)
+	m.updateContent()
+	return m, cmd
+}
+
+func (m Merge) View() string {
+	helpView := m.list.Help.View(m.list)
+	m.list.DecreaseHeight(lipgloss.Height(helpView))
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		style.Top.Render(m.list.View()),
+		m.list.Viewport.View(),
+		style.Bot.Render(helpView),
+	)
+}
+
+func (m Merge) pick(side merge.Side) (tea.Model, tea.Cmd) {
+	if !m.list.ItemSelected() {
+		return m, nil
+	}
+
+	index := m.list.GlobalIndex()
+	m.items[index].Side = side
+	cmd := m.list.SetItem(index, m.items[index])
+	m.updateContent()
+
+	status := fmt.Sprintf("%d conflicts left", m.unresolved())
+	return m, tea.Batch(cmd, m.list.NewStatus(status))
+}
+
+func (m Merge) unresolved() int {
+	unresolved := 0
+	for _, item := range m.items {
+		if item.Side == merge.Unresolved {
+			unresolved++
+		}
+	}
+	return unresolved
+}
+
+func (m Merge) resolutions() merge.Resolutions {
+	resolutions := make(merge.Resolutions, len(m.items))
+	for _, item := range m.items {
+		resolutions[item.Key] = item.Side
+	}
+	return resolutions
+}
+
+func (m *Merge) updateContent() {
+	if !m.list.ItemSelected() {
+		m.list.Viewport.SetContent("")
+		return
+	}
+
+	item := m.items[m.list.GlobalIndex()]
+	label := func(side merge.Side) string {
+		if item.Side == side {
+			return style.Highlight.Render(side.String() + ":")
+		}
+		return style.Faint.Render(side.String() + ":")
+	}
+
+	m.list.Viewport.SetContent(strings.Join([]string{
+		style.Faint.Render("base:") + " " + item.Base,
+		label(merge.Ours) + " " + item.Ours,
+		label(merge.Theirs) + " " + item.Theirs,
+	}, "\n"))
+}
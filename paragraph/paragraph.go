@@ -53,6 +53,9 @@ var (
 		keyset.Add,
 		keyset.Invert,
 		keyset.Status,
+		keyset.Duplicates,
+		keyset.History,
+		keyset.AuditLog,
 	}
 )
 
@@ -86,7 +89,7 @@ func New(
 
 	return Paragraph{
 		Model: teax.Model[Item]{
-			List:    teax.NewList(paragraph.QAs, title, delegate),
+			List:    teax.Guard(teax.NewList(paragraph.QAs, title, delegate), dataset),
 			Coll:    paragraph,
 			Dataset: dataset,
 			Form:    form,
@@ -125,6 +128,9 @@ func (m Paragraph) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch msg := msg.(type) {
 		case tea.KeyMsg:
 			switch {
+			case m.Dataset.ReadOnly() && key.Matches(msg, keyset.Mutating...):
+				return m, nil
+
 			case key.Matches(msg, keyset.Add):
 				if m.List.ItemSelected() {
 					index := m.List.GlobalIndex()
@@ -190,8 +196,7 @@ func (m Paragraph) View() string {
 }
 
 func (m Paragraph) isEmptyID() bool {
-	return !m.InSync &&
-		m.List.ItemSelected() &&
+	return m.List.ItemSelected() &&
 		m.Coll.Get(m.List.GlobalIndex()).IsEmptyID()
 }
 
@@ -207,16 +212,14 @@ func (m Paragraph) fullKeys() func() []key.Binding {
 }
 
 func (m *Paragraph) updateContext() {
-	if !m.InSync {
-		if m.List.ItemSelected() {
-			qa := m.Coll.Get(m.List.GlobalIndex())
-			if len(qa.Answers()) == 0 {
-				m.viewport.Blur()
-			}
-			m.viewport.Highlight(m.context, qa.Answers(), qa.Highlight())
-		} else {
-			m.viewport.SetContent(m.paragraph.Context)
+	if m.List.ItemSelected() {
+		qa := m.Coll.Get(m.List.GlobalIndex())
+		if len(qa.Answers()) == 0 {
+			m.viewport.Blur()
 		}
+		m.viewport.Highlight(m.context, qa.Answers(), qa.Highlight())
+	} else {
+		m.viewport.SetContent(m.paragraph.Context)
 	}
 }
 
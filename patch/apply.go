@@ -0,0 +1,271 @@
+package patch
+
+import (
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrNotFound = errors.New("no value at path")
+	ErrTest     = errors.New("test failed")
+)
+
+// Apply applies the operations of p to doc in order. Nothing is
+// applied unless all of them are, tests included.
+func (p Patch) Apply(doc []byte) ([]byte, error) {
+	var root any
+	if err := json.Unmarshal(doc, &root); err != nil {
+		return nil, err
+	}
+
+	for i, op := range p {
+		var err error
+		if root, err = op.apply(root); err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i+1, op.Op, op.Path, err)
+		}
+	}
+
+	return json.Marshal(root)
+}
+
+func (op Operation) apply(root any) (any, error) {
+	path, err := parse(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case Add, Replace, Test:
+		value, err := decode(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		switch op.Op {
+		case Add:
+			return add(root, path, value)
+		case Replace:
+			return replace(root, path, value)
+		}
+		return root, test(root, path, value)
+
+	case Remove:
+		root, _, err = remove(root, path)
+		return root, err
+
+	case Move, Copy:
+		from, err := parse(op.From)
+		if err != nil {
+			return nil, err
+		}
+
+		var value any
+		if op.Op == Move {
+			if len(from) < len(path) && slices.Equal(from, path[:len(from)]) {
+				return nil, errors.New("can't move a value into itself")
+			}
+			root, value, err = remove(root, from)
+		} else {
+			value, err = get(root, from)
+			if err == nil {
+				value, err = clone(value)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("from %s: %w", op.From, err)
+		}
+		return add(root, path, value)
+	}
+
+	return nil, fmt.Errorf("unknown op %q", op.Op)
+}
+
+// parse splits an RFC 6901 JSON Pointer into its reference tokens.
+func parse(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("pointer %q doesn't start with /", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	unescape := strings.NewReplacer("~1", "/", "~0", "~")
+	for i, t := range tokens {
+		tokens[i] = unescape.Replace(t)
+	}
+	return tokens, nil
+}
+
+func decode(value jsontext.Value) (any, error) {
+	if len(value) == 0 {
+		return nil, errors.New("missing value")
+	}
+	var v any
+	err := json.Unmarshal(value, &v)
+	return v, err
+}
+
+// clone keeps a copied value apart from the original.
+func clone(value any) (any, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return decode(b)
+}
+
+func get(node any, path []string) (any, error) {
+	for _, token := range path {
+		switch n := node.(type) {
+		case map[string]any:
+			value, ok := n[token]
+			if !ok {
+				return nil, ErrNotFound
+			}
+			node = value
+		case []any:
+			i, err := index(token, len(n)-1)
+			if err != nil {
+				return nil, err
+			}
+			node = n[i]
+		default:
+			return nil, ErrNotFound
+		}
+	}
+	return node, nil
+}
+
+func test(root any, path []string, value any) error {
+	actual, err := get(root, path)
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(actual, value) {
+		return ErrTest
+	}
+	return nil
+}
+
+func add(root any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return edit(root, path, func(parent any, token string) (any, error) {
+		switch p := parent.(type) {
+		case map[string]any:
+			p[token] = value
+			return p, nil
+		case []any:
+			if token == "-" {
+				return append(p, value), nil
+			}
+			i, err := index(token, len(p))
+			if err != nil {
+				return nil, err
+			}
+			return slices.Insert(p, i, value), nil
+		}
+		return nil, ErrNotFound
+	})
+}
+
+func replace(root any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return edit(root, path, func(parent any, token string) (any, error) {
+		switch p := parent.(type) {
+		case map[string]any:
+			if _, ok := p[token]; !ok {
+				return nil, ErrNotFound
+			}
+			p[token] = value
+			return p, nil
+		case []any:
+			i, err := index(token, len(p)-1)
+			if err != nil {
+				return nil, err
+			}
+			p[i] = value
+			return p, nil
+		}
+		return nil, ErrNotFound
+	})
+}
+
+// remove takes the value at path out of root.
+func remove(root any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, errors.New("can't remove the whole document")
+	}
+
+	var removed any
+	root, err := edit(root, path, func(parent any, token string) (any, error) {
+		switch p := parent.(type) {
+		case map[string]any:
+			value, ok := p[token]
+			if !ok {
+				return nil, ErrNotFound
+			}
+			removed = value
+			delete(p, token)
+			return p, nil
+		case []any:
+			i, err := index(token, len(p)-1)
+			if err != nil {
+				return nil, err
+			}
+			removed = p[i]
+			return slices.Delete(p, i, i+1), nil
+		}
+		return nil, ErrNotFound
+	})
+	return root, removed, err
+}
+
+// edit changes the parent of the value at path with f, which gets the
+// last token of path.
+func edit(node any, path []string, f func(parent any, token string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return f(node, path[0])
+	}
+
+	child, err := get(node, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	if child, err = edit(child, path[1:], f); err != nil {
+		return nil, err
+	}
+
+	switch n := node.(type) {
+	case map[string]any:
+		n[path[0]] = child
+	case []any:
+		// The index is checked by get
+		i, _ := strconv.Atoi(path[0])
+		n[i] = child
+	}
+	return node, nil
+}
+
+// index parses an array index of up to last.
+func index(token string, last int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || strings.Trim(token, "0123456789") != "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if i > last {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
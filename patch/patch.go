@@ -0,0 +1,252 @@
+package patch
+
+import (
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/donderom/sqwat/squad"
+)
+
+type Op string
+
+const (
+	Add     Op = "add"
+	Remove  Op = "remove"
+	Replace Op = "replace"
+	Move    Op = "move"
+	Copy    Op = "copy"
+	Test    Op = "test"
+)
+
+// Operation is one step of an RFC 6902 JSON Patch.
+type Operation struct {
+	Op    Op             `json:"op"`
+	Path  string         `json:"path"`
+	From  string         `json:"from,omitzero"`
+	Value jsontext.Value `json:"value,omitzero"`
+}
+
+type Patch []Operation
+
+// Path is where the edits of filename are exported.
+func Path(filename string) string {
+	return filename + ".patch.json"
+}
+
+func Read(r io.Reader) (Patch, error) {
+	var p Patch
+	if err := json.UnmarshalRead(r, &p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p Patch) Write(w io.Writer) error {
+	if p == nil {
+		p = Patch{}
+	}
+	return json.MarshalWrite(w, p, jsontext.WithIndent("  "))
+}
+
+// Pointer is the RFC 6901 JSON Pointer to the item at path.
+func Pointer(path ...any) string {
+	var s strings.Builder
+	for _, token := range path {
+		s.WriteByte('/')
+		switch t := token.(type) {
+		case int:
+			s.WriteString(strconv.Itoa(t))
+		default:
+			s.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(fmt.Sprint(t)))
+		}
+	}
+	return s.String()
+}
+
+// Diff is the patch that turns old into new. Every value replaced or
+// removed is tested first so that the patch only applies to the data
+// it was made for.
+func Diff(old, new *squad.SQuAD) (Patch, error) {
+	var d differ
+	if old.Version != new.Version {
+		d.replace(Pointer("version"), old.Version, new.Version)
+	}
+	diffSlices(&d, Pointer("data"), old.Articles, new.Articles, squad.Article.Equal, diffArticles)
+	return d.patch, d.err
+}
+
+// differ collects the operations, the first error stops it.
+type differ struct {
+	patch Patch
+	err   error
+}
+
+func (d *differ) add(path string, value any) {
+	d.append(Operation{Op: Add, Path: path}, value)
+}
+
+func (d *differ) remove(path string, old any) {
+	d.append(Operation{Op: Test, Path: path}, old)
+	d.append(Operation{Op: Remove, Path: path}, nil)
+}
+
+func (d *differ) replace(path string, old, new any) {
+	d.append(Operation{Op: Test, Path: path}, old)
+	d.append(Operation{Op: Replace, Path: path}, new)
+}
+
+func (d *differ) append(op Operation, value any) {
+	if d.err != nil {
+		return
+	}
+	if value != nil {
+		op.Value, d.err = json.Marshal(value)
+	}
+	d.patch = append(d.patch, op)
+}
+
+func diffArticles(d *differ, path string, old, new squad.Article) {
+	if old.Name != new.Name {
+		d.replace(path+Pointer("title"), old.Name, new.Name)
+	}
+	diffSlices(d, path+Pointer("paragraphs"), old.Paragraphs, new.Paragraphs, squad.Paragraph.Equal, diffParagraphs)
+}
+
+func diffParagraphs(d *differ, path string, old, new squad.Paragraph) {
+	if old.Context != new.Context {
+		d.replace(path+Pointer("context"), old.Context, new.Context)
+	}
+	diffSlices(d, path+Pointer("qas"), old.QAs, new.QAs, squad.QA.Equal, diffQAs)
+}
+
+func diffQAs(d *differ, path string, old, new squad.QA) {
+	if old.Id != new.Id {
+		d.replace(path+Pointer("id"), old.Id, new.Id)
+	}
+	if old.Question != new.Question {
+		d.replace(path+Pointer("question"), old.Question, new.Question)
+	}
+	if old.Impossible != new.Impossible {
+		d.replace(path+Pointer("is_impossible"), old.Impossible, new.Impossible)
+	}
+
+	diffSlices(d, path+Pointer("answers"), old.CorrectAnswers, new.CorrectAnswers, equal, diffAnswers)
+
+	// Plausible answers are left out of the file when there are none
+	plausible := path + Pointer("plausible_answers")
+	switch {
+	case len(old.PlausibleAnswers) == 0 && len(new.PlausibleAnswers) > 0:
+		d.add(plausible, new.PlausibleAnswers)
+	case len(old.PlausibleAnswers) > 0 && len(new.PlausibleAnswers) == 0:
+		d.remove(plausible, old.PlausibleAnswers)
+	default:
+		diffSlices(d, plausible, old.PlausibleAnswers, new.PlausibleAnswers, equal, diffAnswers)
+	}
+}
+
+func diffAnswers(d *differ, path string, old, new squad.Answer) {
+	if old.Text != new.Text {
+		d.replace(path+Pointer("text"), old.Text, new.Text)
+	}
+	if old.Start != new.Start {
+		d.replace(path+Pointer("answer_start"), old.Start, new.Start)
+	}
+}
+
+func equal[T comparable](a, b T) bool {
+	return a == b
+}
+
+// diffSlices turns old into new going from the start, the indices are
+// the ones of new for the part done so far. The items in place of
+// others are diffed field by field, the rest are removed or added.
+func diffSlices[T any](
+	d *differ,
+	path string,
+	old, new []T,
+	equal func(a, b T) bool,
+	diffItems func(d *differ, path string, old, new T),
+) {
+	for _, h := range hunks(old, new, equal) {
+		changed := min(h.removed, h.added)
+		for i := range changed {
+			diffItems(d, path+Pointer(h.new+i), old[h.old+i], new[h.new+i])
+		}
+		for i := changed; i < h.removed; i++ {
+			d.remove(path+Pointer(h.new+changed), old[h.old+i])
+		}
+		for i := changed; i < h.added; i++ {
+			d.add(path+Pointer(h.new+i), new[h.new+i])
+		}
+	}
+}
+
+// hunk is a run of removed items of old replaced by added ones of new.
+type hunk struct {
+	old, new       int
+	removed, added int
+}
+
+// hunks finds the runs of items that differ around the longest common
+// subsequence of old and new.
+func hunks[T any](old, new []T, equal func(a, b T) bool) []hunk {
+	// Edits are few so the common ends are left out of the table
+	prefix := 0
+	for prefix < len(old) && prefix < len(new) && equal(old[prefix], new[prefix]) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(old)-prefix && suffix < len(new)-prefix &&
+		equal(old[len(old)-1-suffix], new[len(new)-1-suffix]) {
+		suffix++
+	}
+
+	o, n := old[prefix:len(old)-suffix], new[prefix:len(new)-suffix]
+
+	// lcs[i][j] is the length of the common subsequence of o[i:] and n[j:]
+	lcs := make([][]int, len(o)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(n)+1)
+	}
+	for i := len(o) - 1; i >= 0; i-- {
+		for j := len(n) - 1; j >= 0; j-- {
+			if equal(o[i], n[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var result []hunk
+	current := hunk{old: prefix, new: prefix}
+	flush := func(i, j int) {
+		if current.removed > 0 || current.added > 0 {
+			result = append(result, current)
+		}
+		current = hunk{old: prefix + i, new: prefix + j}
+	}
+
+	i, j := 0, 0
+	for i < len(o) || j < len(n) {
+		switch {
+		case i < len(o) && j < len(n) && equal(o[i], n[j]):
+			i++
+			j++
+			flush(i, j)
+		case j == len(n) || (i < len(o) && lcs[i+1][j] >= lcs[i][j+1]):
+			current.removed++
+			i++
+		default:
+			current.added++
+			j++
+		}
+	}
+	flush(i, j)
+
+	return result
+}
@@ -0,0 +1,155 @@
+package patch_test
+
+import (
+	"encoding/json/v2"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/donderom/sqwat/patch"
+	"github.com/donderom/sqwat/squad"
+)
+
+func dataset() *squad.SQuAD {
+	return &squad.SQuAD{
+		Version: "v2.0",
+		Articles: []squad.Article{
+			{
+				Name: "Go",
+				Paragraphs: []squad.Paragraph{{
+					Context: "Go was designed at Google.",
+					QAs: []squad.QA{
+						{
+							Id:             "q1",
+							Question:       "Who designed Go?",
+							CorrectAnswers: []squad.Answer{{Text: "Google", Start: 19}},
+						},
+						{
+							Id:             "q2",
+							Question:       "What was designed at Google?",
+							CorrectAnswers: []squad.Answer{{Text: "Go", Start: 0}},
+						},
+					},
+				}},
+			},
+			{Name: "Rust", Paragraphs: []squad.Paragraph{{Context: "Rust is a language."}}},
+			{Name: "Zig", Paragraphs: []squad.Paragraph{{Context: "Zig is a language."}}},
+		},
+	}
+}
+
+func apply(t *testing.T, p patch.Patch, data *squad.SQuAD) (*squad.SQuAD, error) {
+	t.Helper()
+
+	doc, err := json.Marshal(data)
+	require.NoError(t, err)
+
+	doc, err = p.Apply(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var result squad.SQuAD
+	require.NoError(t, json.Unmarshal(doc, &result))
+	return &result, nil
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	old := dataset()
+	new := old.Clone()
+	new.Articles[0].Name = "Golang"
+	qa := new.Articles[0].At(0).At(0)
+	qa.Question = "Who made Go?"
+	qa.Impossible = true
+	qa.PlausibleAnswers = qa.CorrectAnswers
+	qa.CorrectAnswers = []squad.Answer{}
+	new.Articles[0].At(0).Remove(1)
+	new.Remove(1)
+	new.Add(squad.Article{Name: "C", Paragraphs: []squad.Paragraph{}})
+
+	p, err := patch.Diff(old, new)
+	require.NoError(t, err)
+	assert.Contains(t, p, patch.Operation{Op: patch.Remove, Path: "/data/1"})
+	assert.Contains(t, p, patch.Operation{Op: patch.Remove, Path: "/data/0/paragraphs/0/qas/1"})
+
+	result, err := apply(t, p, old)
+	require.NoError(t, err)
+	assert.True(t, new.Articles[0].Equal(result.Articles[0]))
+	assert.Equal(t, []string{"Golang", "Zig", "C"}, []string{
+		result.Articles[0].Name, result.Articles[1].Name, result.Articles[2].Name,
+	})
+
+	p, err = patch.Diff(old, old.Clone())
+	require.NoError(t, err)
+	assert.Empty(t, p)
+}
+
+func TestApplyPreconditions(t *testing.T) {
+	t.Parallel()
+
+	old := dataset()
+	new := old.Clone()
+	new.Articles[1].Name = "Rust lang"
+
+	p, err := patch.Diff(old, new)
+	require.NoError(t, err)
+
+	other := dataset()
+	other.Articles[1].Name = "Ferris"
+	_, err = apply(t, p, other)
+	assert.ErrorIs(t, err, patch.ErrTest)
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		doc, patch, result string
+	}{
+		"add to array": {
+			`{"a":[1,2]}`, `[{"op":"add","path":"/a/1","value":3},{"op":"add","path":"/a/-","value":4}]`, `{"a":[1,3,2,4]}`,
+		},
+		"remove": {
+			`{"a":{"b":1,"c":2}}`, `[{"op":"remove","path":"/a/b"}]`, `{"a":{"c":2}}`,
+		},
+		"move": {
+			`{"a":[1,2],"b":[]}`, `[{"op":"move","from":"/a/0","path":"/b/0"}]`, `{"a":[2],"b":[1]}`,
+		},
+		"copy": {
+			`{"a":{"x":1}}`, `[{"op":"copy","from":"/a","path":"/b"},{"op":"replace","path":"/b/x","value":2}]`, `{"a":{"x":1},"b":{"x":2}}`,
+		},
+		"escaped": {
+			`{"a/b":{"~":1}}`, `[{"op":"test","path":"/a~1b/~0","value":1}]`, `{"a/b":{"~":1}}`,
+		},
+	} {
+		p, err := patch.Read(strings.NewReader(tc.patch))
+		require.NoError(t, err, name)
+		result, err := p.Apply([]byte(tc.doc))
+		require.NoError(t, err, name)
+		assert.JSONEq(t, tc.result, string(result), name)
+	}
+
+	for name, ops := range map[string]string{
+		"missing":      `[{"op":"replace","path":"/b","value":1}]`,
+		"out of range": `[{"op":"add","path":"/a/3","value":1}]`,
+		"bad index":    `[{"op":"remove","path":"/a/01"}]`,
+		"into itself":  `[{"op":"move","from":"/a","path":"/a/0"}]`,
+		"unknown":      `[{"op":"swap","path":"/a"}]`,
+	} {
+		p, err := patch.Read(strings.NewReader(ops))
+		require.NoError(t, err, name)
+		_, err = p.Apply([]byte(`{"a":[1,2]}`))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestPointer(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "/data/3/paragraphs/1/qas/0/answers/0", patch.Pointer("data", 3, "paragraphs", 1, "qas", 0, "answers", 0))
+	assert.Equal(t, "/a~1b/~0", patch.Pointer("a/b", "~"))
+}
@@ -20,6 +20,8 @@ type Disambiguated struct {
 	start int
 }
 
+func (d Disambiguated) Start() int { return d.start }
+
 func Disambiguate(start int) tea.Cmd {
 	return bubblon.Cmd(Disambiguated{start: start})
 }
@@ -39,6 +39,9 @@ var (
 		keyset.Next,
 		keyset.Prev,
 		keyset.Status,
+		keyset.Duplicates,
+		keyset.History,
+		keyset.AuditLog,
 	}
 )
 
@@ -67,7 +70,7 @@ func New(
 
 	return Question{
 		Model: teax.Model[Item]{
-			List:     teax.NewList(qa.Answers(), qa.Question, delegate),
+			List:     teax.Guard(teax.NewList(qa.Answers(), qa.Question, delegate), dataset),
 			Coll:     qa,
 			Dataset:  dataset,
 			Form:     form,
@@ -135,15 +138,13 @@ func (m Question) View() string {
 }
 
 func (m *Question) updateContext() {
-	if !m.InSync {
-		if m.List.ItemSelected() {
-			index := m.List.GlobalIndex()
-			answer := m.qa.Answers()[index : index+1]
-			m.viewport.Highlight(m.context, answer, m.qa.Highlight())
-		} else {
-			m.viewport.Blur()
-			m.viewport.SetContent(string(m.context))
-		}
+	if m.List.ItemSelected() {
+		index := m.List.GlobalIndex()
+		answer := m.qa.Answers()[index : index+1]
+		m.viewport.Highlight(m.context, answer, m.qa.Highlight())
+	} else {
+		m.viewport.Blur()
+		m.viewport.SetContent(string(m.context))
 	}
 }
 
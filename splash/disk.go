@@ -0,0 +1,348 @@
+package splash
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/donderom/sqwat/audit"
+	"github.com/donderom/sqwat/auditview"
+	"github.com/donderom/sqwat/diff"
+	"github.com/donderom/sqwat/diffview"
+	"github.com/donderom/sqwat/diskview"
+	"github.com/donderom/sqwat/filestamp"
+	"github.com/donderom/sqwat/journal"
+	"github.com/donderom/sqwat/merge"
+	"github.com/donderom/sqwat/patch"
+	"github.com/donderom/sqwat/squad"
+	"github.com/donderom/sqwat/teax"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/donderom/bubblon"
+)
+
+// disk is the file as it was last read or written.
+type disk struct {
+	// Saves change the stamp while edits are journaled against it
+	mu    sync.Mutex
+	stamp filestamp.Stamp
+	// The file as it was opened, patches are made against it
+	loaded *squad.SQuAD
+}
+
+func (d *disk) setStamp(stamp filestamp.Stamp) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stamp = stamp
+}
+
+// sum is the one of the file the edits are made to.
+func (d *disk) sum() []byte {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.stamp.Sum
+}
+
+var _ diskview.File = dataset{}
+
+func readFile(filename string) (loaded, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return loaded{}, err
+	}
+
+	h := filestamp.NewHash()
+	data, format, err := squad.Read(io.TeeReader(file, h))
+	skipped, err := unmapped(err)
+	if err != nil {
+		_ = file.Close()
+		return loaded{}, err
+	}
+
+	if err = file.Close(); err != nil {
+		return loaded{}, err
+	}
+
+	stamp, err := filestamp.Of(filename, h.Sum(nil))
+	return loaded{dataset: data, format: format, stamp: stamp, skipped: skipped}, err
+}
+
+// unmapped tells the records skipped by a conversion apart from the
+// errors it failed with.
+func unmapped(err error) (squad.Unmapped, error) {
+	if skipped, ok := errors.AsType[squad.Unmapped](err); ok {
+		return skipped, nil
+	}
+	return nil, err
+}
+
+// records lists up to limit of the skipped records.
+func records(skipped squad.Unmapped, limit int) string {
+	limit = min(len(skipped), max(limit, 1))
+	lines := make([]string, 0, limit+1)
+	for _, s := range skipped[:limit] {
+		lines = append(lines, s.String())
+	}
+	if more := len(skipped) - limit; more > 0 {
+		lines = append(lines, fmt.Sprintf("and %d more", more))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// warn tells of the records the format of filename can't hold, the
+// file is saved without them.
+func warn(filename string, format squad.Format, skipped squad.Unmapped) error {
+	if len(skipped) == 0 {
+		return nil
+	}
+
+	records := make([]string, len(skipped))
+	for i, s := range skipped {
+		records[i] = s.String()
+	}
+	return teax.Warning{Err: fmt.Errorf(
+		"%s is saved without %d records %s can't hold: %s",
+		filename, len(skipped), format, strings.Join(records, "; "),
+	)}
+}
+
+// write saves data whatever is on disk and drops the first n edits it
+// has from the ones to save and the journaled ones. The file is replaced
+// at once so that a crash doesn't leave it half-written.
+func (d dataset) write(data *squad.SQuAD, n int, journaled int64) error {
+	file, err := os.CreateTemp(filepath.Dir(d.filename), "."+filepath.Base(d.filename)+".*")
+	if err != nil {
+		return err
+	}
+
+	h := filestamp.NewHash()
+	skipped, err := d.writeTo(file, data, h)
+	if err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return err
+	}
+
+	if err = file.Close(); err == nil {
+		err = os.Rename(file.Name(), d.filename)
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+
+	d.edits.saved(data, n)
+	if d.disk == nil {
+		return warn(d.filename, d.format, skipped)
+	}
+
+	stamp, err := filestamp.Of(d.filename, h.Sum(nil))
+	if err != nil {
+		return err
+	}
+
+	d.disk.setStamp(stamp)
+	if err = journal.Trim(d.filename, journaled, stamp.Sum); err != nil {
+		return err
+	}
+	return warn(d.filename, d.format, skipped)
+}
+
+func (d dataset) writeTo(file *os.File, data *squad.SQuAD, h hash.Hash) (squad.Unmapped, error) {
+	// The file keeps its permissions
+	if info, err := os.Stat(d.filename); err == nil {
+		if err = file.Chmod(info.Mode()); err != nil {
+			return nil, err
+		}
+	}
+
+	// Saves keep the compression of the file too
+	w := squad.Compress(io.MultiWriter(file, h), d.filename)
+	skipped, err := unmapped(data.Write(w, d.format))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+
+	return skipped, file.Sync()
+}
+
+var kinds = map[teax.Change]journal.Kind{
+	teax.Creation:     journal.Created,
+	teax.Modification: journal.Updated,
+	teax.Deletion:     journal.Deleted,
+}
+
+func (d dataset) Journal(edits ...teax.Edit) error {
+	// Data from stdin is written as it is on exit
+	if d.stdin != nil {
+		return nil
+	}
+
+	ops := make([]journal.Op, len(edits))
+	for i, e := range edits {
+		op, err := journal.NewOp(d.data, kinds[e.Change], e.Coll, e.Index)
+		if err != nil {
+			return err
+		}
+		ops[i] = op
+	}
+
+	err := d.edits.add(ops, func() error {
+		// Only files are recovered
+		if d.disk == nil {
+			return nil
+		}
+		return journal.Append(d.filename, d.disk.sum(), ops...)
+	})
+	if err != nil || d.disk == nil {
+		return err
+	}
+
+	// The ops are audited as they are journaled, the item is only
+	// located once
+	entries := make([]audit.Entry, len(ops))
+	for i, op := range ops {
+		entry, err := audit.New(d.data, op, edits[i].Before, d.user)
+		if err != nil {
+			return teax.Warning{Err: err}
+		}
+		entries[i] = entry
+	}
+
+	if err := audit.Append(d.filename, entries...); err != nil {
+		return teax.Warning{Err: err}
+	}
+	return nil
+}
+
+func (d dataset) AuditLog() tea.Model {
+	entries, err := d.auditLog()
+	return auditview.New(d.filename+" audit log", entries, err)
+}
+
+func (d dataset) History(coll any, index int) tea.Model {
+	entries, err := d.auditLog()
+	entries = audit.History(entries, d.data, coll, index)
+	return auditview.New(d.filename+" history", entries, err)
+}
+
+// auditLog is only kept for files.
+func (d dataset) auditLog() ([]audit.Entry, error) {
+	if d.disk == nil {
+		return nil, nil
+	}
+	return audit.Read(d.filename)
+}
+
+func (d dataset) ExportPatch() (string, error) {
+	if d.disk == nil {
+		return "", errors.New("only the edits of a file are exported")
+	}
+
+	p, err := patch.Diff(d.disk.loaded, d.data)
+	if err != nil {
+		return "", err
+	}
+
+	path := patch.Path(d.filename)
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+
+	if err = p.Write(file); err != nil {
+		_ = file.Close()
+		return "", err
+	}
+
+	return path, file.Close()
+}
+
+func (d dataset) journaled() (int64, error) {
+	if d.disk == nil {
+		return 0, nil
+	}
+	return journal.Size(d.filename)
+}
+
+func (d dataset) Pending() tea.Model {
+	var changes []diff.Change
+	if d.disk != nil {
+		changes = diff.Compare(d.edits.last(), d.data)
+	}
+
+	return diffview.NewPending(d.filename+" edits to save", changes, func() tea.Msg {
+		err := d.saves.Flush()
+		switch {
+		case errors.Is(err, teax.ErrModified):
+			return bubblon.Replace(d.Modified())()
+		case teax.IsWarning(err):
+			return tea.Sequence(bubblon.Close, bubblon.Cmd(teax.Saved{Err: err}))()
+		case err != nil:
+			return teax.Saved{Err: err}
+		}
+		return bubblon.Close()
+	})
+}
+
+func (d dataset) Changes() ([]diff.Change, error) {
+	file, err := readFile(d.filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return diff.Compare(d.edits.last(), file.dataset), nil
+}
+
+func (d dataset) Reload() error {
+	return d.saves.Save(func() error {
+		file, err := readFile(d.filename)
+		if err != nil {
+			return err
+		}
+
+		*d.data = *file.dataset
+		d.disk.setStamp(file.stamp)
+		d.edits.reset(file.dataset.Clone())
+		return journal.Clear(d.filename)
+	})
+}
+
+func (d dataset) Overwrite() error {
+	return d.saves.Save(d.overwrite)
+}
+
+// overwrite saves the data as it is in place of the edits to replay.
+func (d dataset) overwrite() error {
+	_, ops, journaled, err := d.edits.take(d.journaled)
+	if err != nil {
+		return err
+	}
+	return d.write(d.data.Clone(), len(ops), journaled)
+}
+
+func (d dataset) Merge(resolutions merge.Resolutions) (merge.Result, error) {
+	file, err := readFile(d.filename)
+	if err != nil {
+		return merge.Result{}, err
+	}
+
+	result := merge.Merge(d.edits.last(), d.data, file.dataset, resolutions)
+	if resolutions == nil && len(result.Conflicts) > 0 {
+		return result, nil
+	}
+
+	return result, d.saves.Save(func() error {
+		*d.data = *result.Data
+		return d.overwrite()
+	})
+}
@@ -0,0 +1,77 @@
+package splash
+
+import (
+	"sync"
+
+	"github.com/donderom/sqwat/journal"
+	"github.com/donderom/sqwat/squad"
+)
+
+// edits are the ones journaled since the data was last saved. Saves
+// replay them on the data as it was then, so the data on the screens
+// is neither copied on every edit nor read while it's being edited.
+type edits struct {
+	// Saves take the edits as they write the journal
+	mu   sync.Mutex
+	base *squad.SQuAD
+	ops  []journal.Op
+}
+
+func newEdits(base *squad.SQuAD) *edits {
+	return &edits{base: base}
+}
+
+// add keeps ops once record goes through.
+func (e *edits) add(ops []journal.Op, record func() error) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := record(); err != nil {
+		return err
+	}
+	e.ops = append(e.ops, ops...)
+	return nil
+}
+
+// take is the data as it was last saved and the edits since, the size
+// of the journal they're in is told by journaled.
+func (e *edits) take(journaled func() (int64, error)) (*squad.SQuAD, []journal.Op, int64, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	size, err := journaled()
+	return e.base, e.ops, size, err
+}
+
+// saved makes data the one to replay the edits on but the first n of
+// them it has.
+func (e *edits) saved(data *squad.SQuAD, n int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.base = data
+	e.ops = e.ops[n:]
+}
+
+// reset drops the edits of data read again.
+func (e *edits) reset(data *squad.SQuAD) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.base = data
+	e.ops = nil
+}
+
+// drop drops the edits once they're reverted.
+func (e *edits) drop() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.ops = nil
+}
+
+// last is the data as it was last saved.
+func (e *edits) last() *squad.SQuAD {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.base
+}
@@ -0,0 +1,86 @@
+package splash
+
+import (
+	"context"
+	"errors"
+
+	"github.com/donderom/sqwat/audit"
+	"github.com/donderom/sqwat/auditview"
+	"github.com/donderom/sqwat/dupview"
+	"github.com/donderom/sqwat/importview"
+	"github.com/donderom/sqwat/squad"
+	"github.com/donderom/sqwat/status"
+	"github.com/donderom/sqwat/teax"
+	"github.com/donderom/sqwat/validation"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// readOnly is the dataset of a file opened with --readonly or locked by
+// someone else. It has no scheduler or journal, so no edit made on its
+// screens reaches the file.
+type readOnly struct {
+	data     *squad.SQuAD
+	filename string
+	// Data from stdin has no audit log or intended marks on disk
+	inMemory bool
+}
+
+var ErrReadOnly = errors.New("read-only mode, changes are not saved")
+
+var (
+	_ teax.Journaler = readOnly{}
+	_ teax.Screens   = readOnly{}
+)
+
+func (r readOnly) ReadOnly() bool {
+	return true
+}
+
+// Save refuses to save, the keys that edit are hidden anyway.
+func (r readOnly) Save() error {
+	return ErrReadOnly
+}
+
+func (r readOnly) Journal(...teax.Edit) error {
+	return ErrReadOnly
+}
+
+func (r readOnly) AuditLog() tea.Model {
+	entries, err := r.auditLog()
+	return auditview.New(r.filename+" audit log", entries, err)
+}
+
+func (r readOnly) History(coll any, index int) tea.Model {
+	entries, err := r.auditLog()
+	entries = audit.History(entries, r.data, coll, index)
+	return auditview.New(r.filename+" history", entries, err)
+}
+
+func (r readOnly) auditLog() ([]audit.Entry, error) {
+	if r.inMemory {
+		return nil, nil
+	}
+	return audit.Read(r.filename)
+}
+
+func (r readOnly) ExportPatch() (string, error) {
+	return "", ErrReadOnly
+}
+
+func (r readOnly) Status(ctx context.Context) tea.Model {
+	results := validation.Run(ctx, r.data)
+	return status.NewStatus(r.filename, r.data, results, r)
+}
+
+func (r readOnly) Duplicates() tea.Model {
+	dups := dupview.New(r.data, r.filename, r)
+	if r.inMemory {
+		return dups.InMemory()
+	}
+	return dups
+}
+
+func (r readOnly) Import() tea.Model {
+	return importview.NewPicker(r.data, r.filename, r)
+}
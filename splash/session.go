@@ -0,0 +1,76 @@
+package splash
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/donderom/sqwat/lockfile"
+	"github.com/donderom/sqwat/squad"
+	"github.com/donderom/sqwat/teax"
+)
+
+// Session is what a run of the program holds until it's over: the
+// locks on the files it edits and the datasets with edits to save.
+type Session struct {
+	mu       sync.Mutex
+	datasets []dataset
+	locks    []*lockfile.Lock
+}
+
+func NewSession() *Session {
+	return &Session{}
+}
+
+// Lock keeps other sessions from editing filename until Close, it is
+// taken before reading the data of NewDataset.
+func (s *Session) Lock(filename string) error {
+	l, err := lockfile.Acquire(filename)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	s.hold(l)
+	return nil
+}
+
+func (s *Session) hold(l *lockfile.Lock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.locks = append(s.locks, l)
+}
+
+// schedule saves the edits of d with saves, the ones still scheduled
+// are saved by Close.
+func (s *Session) schedule(d dataset, saves *teax.Scheduler) dataset {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d.saves = saves
+	s.datasets = append(s.datasets, d)
+	return d
+}
+
+// Close saves the edits of every dataset open and lets other sessions
+// edit their files again.
+func (s *Session) Close() error {
+	s.mu.Lock()
+	datasets, locks := s.datasets, s.locks
+	s.datasets, s.locks = nil, nil
+	s.mu.Unlock()
+
+	var err error
+	for _, d := range datasets {
+		err = errors.Join(err, d.close())
+	}
+	for _, l := range locks {
+		err = errors.Join(err, l.Release())
+	}
+	return err
+}
+
+// NewDataset is the dataset of data read from filename, its edits are
+// saved in the background and by Close at the latest.
+func NewDataset(s *Session, data *squad.SQuAD, filename string, format squad.Format) teax.Dataset {
+	d := dataset{data: data, filename: filename, format: format, edits: newEdits(data.Clone())}
+	return s.schedule(d, teax.NewScheduler(DefaultSaveDelay))
+}
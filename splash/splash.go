@@ -2,49 +2,125 @@ package splash
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/donderom/sqwat/app"
+	"github.com/donderom/sqwat/audit"
+	"github.com/donderom/sqwat/diskview"
+	"github.com/donderom/sqwat/dupview"
+	"github.com/donderom/sqwat/filestamp"
+	"github.com/donderom/sqwat/importview"
+	"github.com/donderom/sqwat/journal"
+	"github.com/donderom/sqwat/keyset"
+	"github.com/donderom/sqwat/lockfile"
 	"github.com/donderom/sqwat/squad"
 	"github.com/donderom/sqwat/status"
 	"github.com/donderom/sqwat/style"
 	"github.com/donderom/sqwat/teax"
 	"github.com/donderom/sqwat/validation"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/donderom/bubblon"
 )
 
 type loaded struct {
 	dataset *squad.SQuAD
+	format  squad.Format
+	stamp   filestamp.Stamp
+	// Records the format couldn't map, they are left out
+	skipped squad.Unmapped
+	// Edits journaled by a session that didn't save them
+	pending []journal.Op
+}
+
+// recovery is the loaded dataset waiting for the pending edits to be
+// replayed or discarded.
+type recovery struct {
+	dataset dataset
+	ops     []journal.Op
+}
+
+type recovered struct {
+	dataset dataset
+	err     error
 }
 
 type failed struct {
 	err error
 }
 
+type locked struct {
+	held lockfile.Held
+}
+
 type Splash struct {
+	// Holds the lock on the file and saves its edits on exit
+	session  *Session
 	spinner  spinner.Model
 	filename string
-	width    int
-	height   int
+	stdin    *Stdin
+	readOnly bool
+	// Set while asking whether to open the file locked by someone else
+	held *lockfile.Held
+	// Set while asking what to do with the edits of a crashed session
+	recovery *recovery
+	// Set while warning of the records left out on load
+	skipped    *loaded
+	saveDelay  time.Duration
+	manualSave bool
+	// Recorded in the audit log as the author of the edits
+	user   string
+	width  int
+	height int
 }
 
 var _ tea.Model = Splash{}
 
-func New(filename string) Splash {
+func New(session *Session, filename string) Splash {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = style.Highlight
 
 	return Splash{
-		spinner:  s,
-		filename: filename,
+		session:   session,
+		spinner:   s,
+		filename:  filename,
+		saveDelay: DefaultSaveDelay,
+		user:      audit.User(),
 	}
 }
 
+// SaveDelay is how long edits wait for others to be saved together.
+func (m Splash) SaveDelay(delay time.Duration) Splash {
+	m.saveDelay = delay
+	return m
+}
+
+// User is the name to audit the edits by.
+func (m Splash) User(name string) Splash {
+	m.user = name
+	return m
+}
+
+// ManualSave keeps the edits in memory until they are saved with ctrl+s.
+func (m Splash) ManualSave() Splash {
+	m.manualSave = true
+	return m
+}
+
+// ReadOnly opens the file in a dataset that can't be saved.
+func (m Splash) ReadOnly() Splash {
+	m.readOnly = true
+	return m
+}
+
 func (m Splash) Init() tea.Cmd {
 	return tea.Batch(m.spinner.Tick, m.load())
 }
@@ -54,10 +130,67 @@ func (m Splash) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case failed:
 		return m, bubblon.Fail(msg.err)
 
+	case locked:
+		m.held = &msg.held
+		return m, nil
+
+	case tea.KeyMsg:
+		switch {
+		case m.held != nil:
+			return m.updateHeld(msg)
+		case m.recovery != nil:
+			return m.updateRecovery(msg)
+		case m.skipped != nil:
+			return m.updateSkipped(msg)
+		}
+
 	case loaded:
-		dataset := dataset{data: msg.dataset, filename: m.filename}
-		model := app.New(msg.dataset, m.filename, dataset)
-		return m, bubblon.Replace(model)
+		if len(msg.skipped) > 0 {
+			m.skipped = &msg
+			return m, nil
+		}
+
+		if m.readOnly {
+			r := readOnly{data: msg.dataset, filename: m.filename}
+			if m.stdin != nil {
+				r.filename, r.inMemory = "stdin", true
+			}
+			return m, bubblon.Replace(app.New(r.data, r.filename, r))
+		}
+
+		// Saves replace the data the edits are replayed on, they never
+		// change it
+		base := msg.dataset.Clone()
+		d := dataset{
+			data:     msg.dataset,
+			filename: m.filename,
+			format:   msg.format,
+			disk:     &disk{stamp: msg.stamp, loaded: base},
+		}
+		if m.stdin != nil {
+			m.stdin.data, m.stdin.format = msg.dataset, msg.format
+			_, d = m.stdin.dataset()
+		}
+		d.edits = newEdits(base)
+		d.user = m.user
+		saves := teax.NewScheduler(m.saveDelay)
+		// Data from stdin without an output file is kept in memory anyway
+		if m.manualSave && (m.stdin == nil || !m.stdin.inMemory()) {
+			saves = teax.NewManualScheduler()
+		}
+		d = m.session.schedule(d, saves)
+
+		if len(msg.pending) > 0 {
+			m.recovery = &recovery{dataset: d, ops: msg.pending}
+			return m, nil
+		}
+		return m, start(d)
+
+	case recovered:
+		if msg.err != nil {
+			return m, bubblon.Fail(msg.err)
+		}
+		return m, start(msg.dataset)
 
 	case tea.WindowSizeMsg:
 		h, v := style.App.GetFrameSize()
@@ -70,7 +203,95 @@ func (m Splash) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+func (m Splash) updateHeld(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, openReadOnly):
+		m.held = nil
+		m.readOnly = true
+		return m, tea.Batch(m.spinner.Tick, m.load())
+
+	case key.Matches(msg, keyset.Esc), key.Matches(msg, keyset.Quit):
+		return m, tea.Quit
+	}
+
+	return m, nil
+}
+
+func (m Splash) updateRecovery(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	r := *m.recovery
+
+	switch {
+	case key.Matches(msg, replay):
+		m.recovery = nil
+		return m, tea.Batch(m.spinner.Tick, func() tea.Msg {
+			err := r.dataset.replay(r.ops)
+			if err == nil {
+				err = r.dataset.Save()
+			}
+			if teax.IsWarning(err) {
+				err = nil
+			}
+			return recovered{dataset: r.dataset, err: err}
+		})
+
+	case key.Matches(msg, keyset.Discard):
+		if err := journal.Clear(m.filename); err != nil {
+			return m, bubblon.Fail(err)
+		}
+		return m, start(r.dataset)
+
+	case key.Matches(msg, keyset.Esc), key.Matches(msg, keyset.Quit):
+		return m, tea.Quit
+	}
+
+	return m, nil
+}
+
+func (m Splash) updateSkipped(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, openSkipped):
+		loaded := *m.skipped
+		loaded.skipped = nil
+		m.skipped = nil
+		return m.Update(loaded)
+
+	case key.Matches(msg, keyset.Esc), key.Matches(msg, keyset.Quit):
+		return m, tea.Quit
+	}
+
+	return m, nil
+}
+
 func (m Splash) View() string {
+	if m.recovery != nil {
+		return style.Center(m.width, m.height).Render(lipgloss.JoinVertical(
+			lipgloss.Center,
+			fmt.Sprintf("Unsaved edits of a session that ended on %s: %d", m.filename, len(m.recovery.ops)),
+			"",
+			help.New().ShortHelpView([]key.Binding{replay, keyset.Discard, keyset.Quit}),
+		))
+	}
+
+	if m.skipped != nil {
+		return style.Center(m.width, m.height).Render(lipgloss.JoinVertical(
+			lipgloss.Center,
+			fmt.Sprintf("%s: %s, saves leave them out", m.filename, m.skipped.skipped),
+			"",
+			records(m.skipped.skipped, m.height-4),
+			"",
+			help.New().ShortHelpView([]key.Binding{openSkipped, keyset.Quit}),
+		))
+	}
+
+	if m.held != nil {
+		return style.Center(m.width, m.height).Render(lipgloss.JoinVertical(
+			lipgloss.Center,
+			fmt.Sprintf("%s is %s", m.filename, m.held),
+			"",
+			help.New().ShortHelpView([]key.Binding{openReadOnly, keyset.Quit}),
+		))
+	}
+
 	return style.Center(m.width, m.height).Render(
 		fmt.Sprintf("%s Loading file %s...", m.spinner.View(), m.filename),
 	)
@@ -78,49 +299,190 @@ func (m Splash) View() string {
 
 func (m Splash) load() tea.Cmd {
 	return func() tea.Msg {
-		file, err := os.Open(m.filename)
-		if err != nil {
-			return failed{err: err}
+		if m.stdin != nil {
+			dataset, format, err := squad.Read(os.Stdin)
+			skipped, err := unmapped(err)
+			if err != nil {
+				return failed{err: err}
+			}
+			return loaded{dataset: dataset, format: format, skipped: skipped}
 		}
 
-		dataset, err := squad.Load(file)
+		if !m.readOnly {
+			l, err := lockfile.Acquire(m.filename)
+			if held, ok := errors.AsType[lockfile.Held](err); ok {
+				return locked{held: held}
+			}
+			if err != nil {
+				return failed{err: err}
+			}
+			m.session.hold(l)
+		}
+
+		file, err := readFile(m.filename)
 		if err != nil {
 			return failed{err: err}
 		}
 
-		if err = file.Close(); err != nil {
-			return failed{err: err}
+		// Journals of others are theirs to recover
+		var pending []journal.Op
+		if !m.readOnly {
+			pending, err = journal.Pending(m.filename, file.stamp.Sum)
+			if err == nil && len(pending) == 0 {
+				// A journal of another version of the file is saved
+				// already
+				err = journal.Clear(m.filename)
+			}
+			if err != nil {
+				return failed{err: err}
+			}
 		}
 
-		return loaded{dataset: dataset}
+		file.pending = pending
+		return file
 	}
 }
 
+var (
+	openReadOnly = keyset.NewEnter("open read-only")
+	replay       = keyset.NewEnter("replay")
+	openSkipped  = keyset.NewEnter("open without them")
+)
+
+const DefaultSaveDelay = 500 * time.Millisecond
+
+func start(d dataset) tea.Cmd {
+	return bubblon.Replace(app.New(d.data, d.filename, d))
+}
+
 type dataset struct {
 	data     *squad.SQuAD
 	filename string
+	// Saves keep the format the file was loaded in
+	format squad.Format
+	// Data from stdin without an output file is saved in memory
+	stdin *Stdin
+	// Known state of the file to tell changes by others
+	disk *disk
+	// Edits to replay on the data as it was last saved
+	edits *edits
+	saves *teax.Scheduler
+	user  string
 }
 
-var _ teax.Dataset = dataset{}
+var (
+	_ teax.Saver     = dataset{}
+	_ teax.Journaler = dataset{}
+	_ teax.Screens   = dataset{}
+)
+
+// close saves the scheduled edits, the ones waiting for a manual save
+// are left in the journal.
+func (d dataset) close() error {
+	if !d.saves.Manual() {
+		return d.saves.Flush()
+	}
+
+	if d.saves.Dirty() {
+		return fmt.Errorf("edits of %s are not saved, open it again to replay them", d.filename)
+	}
+	return nil
+}
 
 func (d dataset) Save() error {
-	file, err := os.Create(d.filename)
+	return d.saves.Save(d.flush)
+}
+
+func (d dataset) Schedule(undo func()) tea.Cmd {
+	return d.saves.Schedule(d.flush, undo)
+}
+
+func (d dataset) Scheduler() *teax.Scheduler {
+	return d.saves
+}
+
+// flush saves the edits so far replayed on the data as it was last
+// saved unless the file has been changed by someone else. The edits
+// made while saving stay in the journal.
+func (d dataset) flush() error {
+	if d.stdin != nil {
+		d.stdin.changed = true
+		return nil
+	}
+
+	base, ops, journaled, err := d.edits.take(d.journaled)
 	if err != nil {
 		return err
 	}
 
-	if err = d.data.Save(file); err != nil {
+	// Replay works on a copy of the data
+	data := *base
+	if err = journal.Replay(&data, ops); err != nil {
 		return err
 	}
 
-	if err = file.Close(); err != nil {
-		return err
+	if d.disk != nil {
+		changed, err := d.disk.stamp.Changed(d.filename)
+		if err != nil {
+			return err
+		}
+		if changed {
+			return teax.ErrModified
+		}
 	}
 
-	return nil
+	return d.write(&data, len(ops), journaled)
+}
+
+// replay applies the journaled ops of a session that ended to the data
+// to be saved with the other edits.
+func (d dataset) replay(ops []journal.Op) error {
+	return d.edits.add(ops, func() error {
+		return journal.Replay(d.data, ops)
+	})
+}
+
+// Revert reverts the edits of a failed save along with their journal,
+// they may be on any screen so the app starts over.
+func (d dataset) Revert() (tea.Model, error) {
+	err := d.saves.Revert()
+	d.edits.drop()
+	if d.disk != nil {
+		err = errors.Join(err, journal.Clear(d.filename))
+	}
+	return app.New(d.data, d.filename, d), err
+}
+
+// Discard drops the edits not saved yet along with their journal.
+func (d dataset) Discard() error {
+	d.saves.Discard()
+	if d.disk == nil {
+		return nil
+	}
+	return journal.Clear(d.filename)
+}
+
+func (d dataset) ReadOnly() bool {
+	return false
 }
 
 func (d dataset) Status(ctx context.Context) tea.Model {
 	results := validation.Run(ctx, d.data)
 	return status.NewStatus(d.filename, d.data, results, d)
 }
+
+func (d dataset) Duplicates() tea.Model {
+	dups := dupview.New(d.data, d.filename, d)
+	if d.stdin != nil {
+		return dups.InMemory()
+	}
+	return dups
+}
+
+func (d dataset) Import() tea.Model {
+	return importview.NewPicker(d.data, d.filename, d)
+}
+
+func (d dataset) Modified() tea.Model {
+	return diskview.New(d.data, d.filename, d, d)
+}
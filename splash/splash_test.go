@@ -0,0 +1,101 @@
+package splash_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/donderom/sqwat/splash"
+	"github.com/donderom/sqwat/squad"
+	"github.com/donderom/sqwat/teax"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClose(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	left, right := filepath.Join(dir, "left.json"), filepath.Join(dir, "right.json")
+	session := splash.NewSession()
+
+	for _, filename := range []string{left, right} {
+		data := &squad.SQuAD{Version: "v2.0", Articles: []squad.Article{}}
+		d := splash.NewDataset(session, data, filename, squad.JSON)
+		data.Articles = append(data.Articles, squad.Article{Name: filename, Paragraphs: []squad.Paragraph{}})
+		require.NoError(t, d.(teax.Journaler).Journal(teax.Edit{Change: teax.Creation, Coll: data, Index: 0}))
+		d.(teax.Saver).Schedule(nil)
+	}
+
+	require.NoError(t, session.Close())
+
+	for _, filename := range []string{left, right} {
+		file, err := os.Open(filename)
+		require.NoError(t, err)
+		defer file.Close()
+
+		data, _, err := squad.Read(file)
+		require.NoError(t, err)
+		require.Len(t, data.Articles, 1)
+		assert.Equal(t, filename, data.Articles[0].Name)
+	}
+}
+
+func TestSaveJournaled(t *testing.T) {
+	t.Parallel()
+
+	filename := filepath.Join(t.TempDir(), "data.json")
+	data := &squad.SQuAD{Version: "v2.0", Articles: []squad.Article{}}
+	d := splash.NewDataset(splash.NewSession(), data, filename, squad.JSON)
+
+	data.Articles = append(data.Articles, squad.Article{Name: "Go", Paragraphs: []squad.Paragraph{}})
+	require.NoError(t, d.(teax.Journaler).Journal(teax.Edit{Change: teax.Creation, Coll: data, Index: 0}))
+	// Edits are saved once journaled
+	data.Articles = append(data.Articles, squad.Article{Name: "Rust", Paragraphs: []squad.Paragraph{}})
+	require.NoError(t, d.(teax.Saver).Save())
+
+	file, err := os.Open(filename)
+	require.NoError(t, err)
+	defer file.Close()
+
+	saved, _, err := squad.Read(file)
+	require.NoError(t, err)
+	require.Len(t, saved.Articles, 1)
+	assert.Equal(t, "Go", saved.Articles[0].Name)
+}
+
+func TestSaveUnmapped(t *testing.T) {
+	t.Parallel()
+
+	filename := filepath.Join(t.TempDir(), "data.jsonl")
+	data := &squad.SQuAD{
+		Articles: []squad.Article{{
+			Name: "Go",
+			Paragraphs: []squad.Paragraph{{
+				Context: "Go was designed at Google.",
+				QAs: []squad.QA{
+					{Id: "1", Question: "Where?", CorrectAnswers: []squad.Answer{{Text: "Google", Start: 19}}},
+					{Id: "2", Question: "Who?", CorrectAnswers: []squad.Answer{{Text: "Pike", Start: 40}}},
+				},
+			}},
+		}},
+	}
+
+	d, ok := splash.NewDataset(splash.NewSession(), data, filename, squad.Doccano).(teax.Saver)
+	require.True(t, ok)
+
+	err := d.Save()
+	assert.True(t, teax.IsWarning(err))
+	assert.ErrorContains(t, err, "2: answer out of context")
+
+	file, err := os.Open(filename)
+	require.NoError(t, err)
+	defer file.Close()
+
+	saved, _, err := squad.Read(file)
+	require.NoError(t, err)
+	require.Len(t, saved.Articles, 1)
+	require.Len(t, saved.Articles[0].Paragraphs[0].QAs, 1)
+	assert.Equal(t, "1", saved.Articles[0].Paragraphs[0].QAs[0].Id)
+}
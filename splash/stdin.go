@@ -0,0 +1,76 @@
+package splash
+
+import (
+	"os"
+
+	"github.com/donderom/sqwat/squad"
+)
+
+// Stdin is the data piped into the program. Saves go to the output file
+// when there is one, otherwise the data is kept in memory until WriteTo
+// once the program is over.
+type Stdin struct {
+	output  string
+	data    *squad.SQuAD
+	format  squad.Format
+	changed bool
+}
+
+func NewStdin(session *Session, output string) (Splash, *Stdin) {
+	stdin := &Stdin{output: output}
+	m := New(session, "stdin")
+	m.stdin = stdin
+	return m, stdin
+}
+
+// Changed tells whether there are saves that are only in memory.
+func (s *Stdin) Changed() bool {
+	return s.changed
+}
+
+func (s *Stdin) Output() string {
+	return s.output
+}
+
+// WriteTo writes the data in the format it was read in, - is stdout.
+// The records the format can't hold are left out with a teax.Warning.
+func (s *Stdin) WriteTo(path string) error {
+	if path == "-" {
+		skipped, err := unmapped(s.data.Write(os.Stdout, s.format))
+		if err != nil {
+			return err
+		}
+		return warn("stdout", s.format, skipped)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	w := squad.Compress(file, path)
+	skipped, err := unmapped(s.data.Write(w, s.format))
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	if err = file.Close(); err != nil {
+		return err
+	}
+	return warn(path, s.format, skipped)
+}
+
+func (s *Stdin) inMemory() bool {
+	return s.output == "" || s.output == "-"
+}
+
+func (s *Stdin) dataset() (string, dataset) {
+	if s.inMemory() {
+		return "stdin", dataset{data: s.data, filename: "stdin", format: s.format, stdin: s}
+	}
+	return s.output, dataset{data: s.data, filename: s.output, format: s.format}
+}
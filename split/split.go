@@ -0,0 +1,195 @@
+package split
+
+import (
+	"errors"
+	"math/rand/v2"
+
+	"github.com/donderom/sqwat/squad"
+)
+
+var (
+	ErrNoSizes = errors.New("either ratios or counts are required")
+	ErrSizes   = errors.New("sizes must be positive")
+	ErrNames   = errors.New("number of names must match number of sizes")
+)
+
+type Options struct {
+	Names []string
+	// Relative sizes of partitions in questions
+	Ratios []float64
+	// Absolute sizes of partitions in questions, articles left over
+	// once every partition is full are not used
+	Counts []int
+	// Keep the ratio of impossible questions the same in every partition
+	Stratify bool
+	Seed     uint64
+}
+
+type Partition struct {
+	Name string
+	Data *squad.SQuAD
+}
+
+type Stats struct {
+	Articles   int
+	Questions  int
+	Impossible int
+}
+
+func Count(s *squad.SQuAD) Stats {
+	stats := Stats{Articles: len(s.Articles)}
+	for _, a := range s.Articles {
+		pos, imp := count(a)
+		stats.Questions += pos + imp
+		stats.Impossible += imp
+	}
+	return stats
+}
+
+// Split partitions s by whole articles so that the same context never
+// ends up in two partitions.
+func Split(s *squad.SQuAD, opts Options) ([]Partition, error) {
+	sizes, err := targets(s, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(opts.Names) != len(sizes) {
+		return nil, ErrNames
+	}
+
+	partitions := make([]Partition, len(sizes))
+	for i, name := range opts.Names {
+		partitions[i] = Partition{Name: name, Data: &squad.SQuAD{Version: s.Version}}
+	}
+
+	total := Count(s)
+	impRatio := 0.0
+	if total.Questions > 0 {
+		impRatio = float64(total.Impossible) / float64(total.Questions)
+	}
+
+	buckets := make([]bucket, len(sizes))
+	for i, size := range sizes {
+		buckets[i] = bucket{
+			size:       size,
+			impossible: size * impRatio,
+			possible:   size * (1 - impRatio),
+		}
+	}
+
+	order := rand.New(rand.NewPCG(opts.Seed, opts.Seed)).Perm(len(s.Articles))
+	assigned := make([]int, len(s.Articles))
+
+	for _, i := range order {
+		assigned[i] = -1
+		pos, imp := count(s.Articles[i])
+
+		best, bestScore := -1, 0.0
+		for j, b := range buckets {
+			score := b.score(pos, imp, opts.Stratify)
+			if opts.Counts != nil && b.full() {
+				continue
+			}
+			if best == -1 || score > bestScore {
+				best, bestScore = j, score
+			}
+		}
+
+		if best == -1 {
+			continue
+		}
+
+		buckets[best].add(pos, imp)
+		assigned[i] = best
+	}
+
+	// Keep the original order of articles
+	for i, p := range assigned {
+		if p != -1 {
+			partitions[p].Data.Add(s.Articles[i])
+		}
+	}
+
+	return partitions, nil
+}
+
+func targets(s *squad.SQuAD, opts Options) ([]float64, error) {
+	switch {
+	case opts.Counts != nil:
+		sizes := make([]float64, len(opts.Counts))
+		for i, c := range opts.Counts {
+			if c <= 0 {
+				return nil, ErrSizes
+			}
+			sizes[i] = float64(c)
+		}
+		return sizes, nil
+
+	case opts.Ratios != nil:
+		sum := 0.0
+		for _, r := range opts.Ratios {
+			if r <= 0 {
+				return nil, ErrSizes
+			}
+			sum += r
+		}
+
+		total := float64(Count(s).Questions)
+		sizes := make([]float64, len(opts.Ratios))
+		for i, r := range opts.Ratios {
+			sizes[i] = r / sum * total
+		}
+		return sizes, nil
+	}
+
+	return nil, ErrNoSizes
+}
+
+type bucket struct {
+	size       float64
+	possible   float64
+	impossible float64
+	curPos     float64
+	curImp     float64
+}
+
+// score tells how much the bucket lacks questions like the ones
+// of the article, the higher the better.
+func (b bucket) score(pos, imp int, stratify bool) float64 {
+	if !stratify || pos+imp == 0 {
+		return fill(b.size, b.curPos+b.curImp)
+	}
+
+	return fill(b.possible, b.curPos)*float64(pos) +
+		fill(b.impossible, b.curImp)*float64(imp)
+}
+
+func (b *bucket) add(pos, imp int) {
+	b.curPos += float64(pos)
+	b.curImp += float64(imp)
+}
+
+func (b bucket) full() bool {
+	return b.curPos+b.curImp >= b.size
+}
+
+func fill(target, current float64) float64 {
+	if target == 0 {
+		return -current
+	}
+	return (target - current) / target
+}
+
+func count(a squad.Article) (possible, impossible int) {
+	for _, p := range a.Paragraphs {
+		for _, qa := range p.QAs {
+			if qa.Impossible {
+				impossible++
+			} else {
+				possible++
+			}
+		}
+	}
+	return possible, impossible
+}
@@ -0,0 +1,134 @@
+package split_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/donderom/sqwat/split"
+	"github.com/donderom/sqwat/squad"
+)
+
+func TestSplit(t *testing.T) {
+	t.Parallel()
+
+	names := []string{"train", "dev"}
+
+	t.Run("ratios", func(t *testing.T) {
+		t.Parallel()
+
+		data := dataset(100, 4, 1)
+		opts := split.Options{Names: names, Ratios: []float64{0.8, 0.2}, Seed: 1}
+		partitions, err := split.Split(data, opts)
+		require.NoError(t, err)
+		require.Len(t, partitions, 2)
+
+		train, dev := split.Count(partitions[0].Data), split.Count(partitions[1].Data)
+		assert.Equal(t, 100, train.Articles+dev.Articles)
+		assert.Equal(t, 400, train.Questions)
+		assert.Equal(t, 100, dev.Questions)
+		assert.Equal(t, "v2.0", partitions[1].Data.Version)
+	})
+
+	t.Run("no leakage", func(t *testing.T) {
+		t.Parallel()
+
+		data := dataset(50, 3, 1)
+		opts := split.Options{Names: names, Ratios: []float64{1, 1}, Seed: 7}
+		partitions, err := split.Split(data, opts)
+		require.NoError(t, err)
+
+		contexts := make(map[string]string)
+		for _, p := range partitions {
+			for _, a := range p.Data.Articles {
+				for _, para := range a.Paragraphs {
+					other, ok := contexts[para.Context]
+					assert.False(t, ok && other != p.Name, "context leaked")
+					contexts[para.Context] = p.Name
+				}
+			}
+		}
+	})
+
+	t.Run("reproducible", func(t *testing.T) {
+		t.Parallel()
+
+		opts := split.Options{Names: names, Ratios: []float64{0.5, 0.5}, Seed: 42}
+		a, err := split.Split(dataset(30, 2, 1), opts)
+		require.NoError(t, err)
+		b, err := split.Split(dataset(30, 2, 1), opts)
+		require.NoError(t, err)
+		assert.Equal(t, a, b)
+	})
+
+	t.Run("counts", func(t *testing.T) {
+		t.Parallel()
+
+		opts := split.Options{Names: names, Counts: []int{10, 10}, Seed: 1}
+		partitions, err := split.Split(dataset(20, 2, 0), opts)
+		require.NoError(t, err)
+		assert.Equal(t, 10, split.Count(partitions[0].Data).Questions)
+		assert.Equal(t, 10, split.Count(partitions[1].Data).Questions)
+	})
+
+	t.Run("stratify", func(t *testing.T) {
+		t.Parallel()
+
+		// Every other article has only impossible questions
+		data := dataset(40, 2, 0)
+		for i := range data.Articles {
+			if i%2 == 0 {
+				for j := range data.At(i).At(0).QAs {
+					data.At(i).At(0).At(j).Impossible = true
+				}
+			}
+		}
+
+		opts := split.Options{
+			Names:    names,
+			Ratios:   []float64{0.5, 0.5},
+			Stratify: true,
+			Seed:     3,
+		}
+		partitions, err := split.Split(data, opts)
+		require.NoError(t, err)
+
+		for _, p := range partitions {
+			stats := split.Count(p.Data)
+			assert.Equal(t, stats.Questions/2, stats.Impossible)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := split.Split(dataset(1, 1, 0), split.Options{Names: names})
+		require.ErrorIs(t, err, split.ErrNoSizes)
+
+		_, err = split.Split(dataset(1, 1, 0), split.Options{Names: names, Ratios: []float64{1, 0}})
+		require.ErrorIs(t, err, split.ErrSizes)
+
+		_, err = split.Split(dataset(1, 1, 0), split.Options{Names: names, Ratios: []float64{1}})
+		require.ErrorIs(t, err, split.ErrNames)
+	})
+}
+
+// dataset generates articles with the given number of possible and
+// impossible questions each.
+func dataset(articles, possible, impossible int) *squad.SQuAD {
+	data := &squad.SQuAD{Version: "v2.0"}
+	for i := range articles {
+		p := squad.Paragraph{Context: fmt.Sprintf("Context %d", i)}
+		for j := range possible + impossible {
+			p.Add(squad.QA{
+				Id:         fmt.Sprintf("%d-%d", i, j),
+				Question:   fmt.Sprintf("Question %d?", j),
+				Impossible: j >= possible,
+			})
+		}
+		data.Add(squad.Article{Name: fmt.Sprintf("Article %d", i), Paragraphs: []squad.Paragraph{p}})
+	}
+	return data
+}
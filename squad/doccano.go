@@ -0,0 +1,166 @@
+package squad
+
+import (
+	"bufio"
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const (
+	docAnswer    = "ANSWER"
+	docPlausible = "PLAUSIBLE"
+)
+
+type docRecord struct {
+	Text       string             `json:"text"`
+	Label      [][]jsontext.Value `json:"label"`
+	Title      string             `json:"title"`
+	Question   string             `json:"question"`
+	Id         string             `json:"qa_id"`
+	Impossible bool               `json:"is_impossible"`
+}
+
+// LoadDoccano reads doccano sequence labeling JSON Lines with a record
+// per question, the question and its metadata are extra fields and
+// answers are [start, end, label] spans over the text.
+func LoadDoccano(r io.Reader) (*SQuAD, error) {
+	b := newBuilder()
+	var unmapped Unmapped
+
+	err := eachLine(r, func(line int, data []byte) error {
+		var record docRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			unmapped = append(unmapped, skip(line, err.Error()))
+			return nil
+		}
+
+		qa, reason := record.qa()
+		if reason != "" {
+			unmapped = append(unmapped, skip(line, reason))
+			return nil
+		}
+
+		b.add(record.Title, record.Text, qa)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return b.squad, unmapped.Err()
+}
+
+func (record docRecord) qa() (QA, string) {
+	if record.Text == "" {
+		return QA{}, "no text"
+	}
+
+	if record.Question == "" {
+		return QA{}, "no question"
+	}
+
+	qa := QA{
+		Id:             record.Id,
+		Question:       record.Question,
+		CorrectAnswers: []Answer{},
+		Impossible:     record.Impossible,
+	}
+	if qa.IsEmptyID() {
+		qa.GenerateID()
+	}
+
+	context := []rune(record.Text)
+	for _, label := range record.Label {
+		start, end, name, err := docSpan(label)
+		if err != nil {
+			return QA{}, err.Error()
+		}
+
+		if start < 0 || end < start || end > len(context) {
+			return QA{}, fmt.Sprintf("span [%d, %d] out of text", start, end)
+		}
+
+		answer := Answer{Text: string(context[start:end]), Start: start}
+		switch name {
+		case docAnswer:
+			qa.CorrectAnswers = append(qa.CorrectAnswers, answer)
+		case docPlausible:
+			qa.PlausibleAnswers = append(qa.PlausibleAnswers, answer)
+		default:
+			return QA{}, fmt.Sprintf("unknown label %q", name)
+		}
+	}
+
+	return qa, ""
+}
+
+func docSpan(label []jsontext.Value) (start, end int, name string, err error) {
+	if len(label) != 3 {
+		return 0, 0, "", errors.New("label is not [start, end, label]")
+	}
+
+	if err = json.Unmarshal(label[0], &start); err != nil {
+		return 0, 0, "", err
+	}
+
+	if err = json.Unmarshal(label[1], &end); err != nil {
+		return 0, 0, "", err
+	}
+
+	err = json.Unmarshal(label[2], &name)
+	return start, end, name, err
+}
+
+// SaveDoccano writes a record per question.
+func (s *SQuAD) SaveDoccano(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	var unmapped Unmapped
+
+	err := eachQA(s, func(a Article, p Paragraph, qa QA) error {
+		context := []rune(p.Context)
+		record := docRecord{
+			Text:       p.Context,
+			Label:      [][]jsontext.Value{},
+			Title:      a.Name,
+			Question:   qa.Question,
+			Id:         qa.Id,
+			Impossible: qa.Impossible,
+		}
+
+		for _, answers := range []struct {
+			label string
+			list  []Answer
+		}{
+			{docAnswer, qa.CorrectAnswers},
+			{docPlausible, qa.PlausibleAnswers},
+		} {
+			for _, answer := range answers.list {
+				if !inContext(context, answer) {
+					unmapped = append(unmapped, Skipped{Record: qa.Id, Reason: "answer out of context"})
+					return nil
+				}
+
+				record.Label = append(record.Label, []jsontext.Value{
+					jsontext.Value(strconv.Itoa(answer.From())),
+					jsontext.Value(strconv.Itoa(answer.To())),
+					jsontext.Value(strconv.Quote(answers.label)),
+				})
+			}
+		}
+
+		return writeLine(bw, record)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = bw.Flush(); err != nil {
+		return err
+	}
+
+	return unmapped.Err()
+}
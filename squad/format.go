@@ -0,0 +1,277 @@
+package squad
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+	"io"
+)
+
+type Format uint8
+
+const (
+	// The original SQuAD layout, a single JSON document
+	JSON Format = iota
+	// Hugging Face datasets layout, one JSON object per question
+	HuggingFace
+	// Label Studio tasks, a JSON array with a task per paragraph
+	LabelStudio
+	// doccano sequence labeling, one JSON object per question
+	Doccano
+	// Prodigy spans, one JSON object per question
+	Prodigy
+	// SQuAD with an article per line after a version header
+	JSONL
+)
+
+var formats = []Format{JSON, HuggingFace, LabelStudio, Doccano, Prodigy, JSONL}
+
+// Skipped is a record a conversion could not map.
+type Skipped struct {
+	// Line of the input or question ID of the output
+	Record string
+	Reason string
+}
+
+func (s Skipped) String() string {
+	return s.Record + ": " + s.Reason
+}
+
+// Unmapped is returned along with the converted data when some records
+// were skipped.
+type Unmapped []Skipped
+
+func (u Unmapped) Error() string {
+	return fmt.Sprintf("%d records could not be mapped", len(u))
+}
+
+func (u Unmapped) Err() error {
+	if len(u) == 0 {
+		return nil
+	}
+	return u
+}
+
+func (f Format) String() string {
+	switch f {
+	case JSON:
+		return "json"
+	case HuggingFace:
+		return "huggingface"
+	case LabelStudio:
+		return "labelstudio"
+	case Doccano:
+		return "doccano"
+	case Prodigy:
+		return "prodigy"
+	case JSONL:
+		return "jsonl"
+	}
+	return "unknown"
+}
+
+// ParseFormat is the format with the name String returns.
+func ParseFormat(name string) (Format, bool) {
+	for _, f := range formats {
+		if f.String() == name {
+			return f, true
+		}
+	}
+	return JSON, false
+}
+
+// Detect tells the format by the first JSON value and its keys, data
+// may be just the start of the input.
+func Detect(data []byte) Format {
+	dec := jsontext.NewDecoder(bytes.NewReader(data))
+
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return JSON
+	}
+
+	if tok.Kind() == '[' {
+		return LabelStudio
+	}
+
+	if tok.Kind() != '{' {
+		return JSON
+	}
+
+	keys := make(map[string]bool)
+	for dec.PeekKind() == '"' {
+		name, err := dec.ReadToken()
+		if err != nil {
+			break
+		}
+		keys[name.String()] = true
+
+		if err = dec.SkipValue(); err != nil {
+			break
+		}
+	}
+
+	switch {
+	case keys["data"]:
+		return JSON
+	case keys["version"] || keys["paragraphs"]:
+		return JSONL
+	case keys["label"] || keys["qa_id"]:
+		return Doccano
+	case keys["spans"] || keys["answer"] || keys["meta"]:
+		return Prodigy
+	case keys["question"] || keys["context"]:
+		return HuggingFace
+	}
+
+	return JSON
+}
+
+// detectSize is how much of the input Detect looks at, enough for the
+// first line of the line-oriented formats and the keys of the others.
+const detectSize = 64 * 1024
+
+// Read loads a dataset in any of the supported formats, gzipped or not,
+// streaming the input to the reader of the format. When some records
+// can't be mapped the data is returned with an Unmapped error.
+func Read(r io.Reader) (*SQuAD, Format, error) {
+	r, err := Decompress(r)
+	if err != nil {
+		return nil, JSON, err
+	}
+
+	br := bufio.NewReaderSize(r, detectSize)
+	head, err := br.Peek(detectSize)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, JSON, err
+	}
+
+	format := Detect(head)
+	var s *SQuAD
+
+	switch format {
+	case HuggingFace:
+		s, err = LoadHuggingFace(br)
+	case LabelStudio:
+		s, err = LoadLabelStudio(br)
+	case Doccano:
+		s, err = LoadDoccano(br)
+	case Prodigy:
+		s, err = LoadProdigy(br)
+	case JSONL:
+		s, err = LoadJSONL(br)
+	default:
+		s, err = Load(br)
+	}
+
+	return s, format, err
+}
+
+// Write saves the dataset in the given format. Questions that can't be
+// written are listed in an Unmapped error.
+func (s *SQuAD) Write(w io.Writer, f Format) error {
+	switch f {
+	case HuggingFace:
+		return s.SaveHuggingFace(w)
+	case LabelStudio:
+		return s.SaveLabelStudio(w)
+	case Doccano:
+		return s.SaveDoccano(w)
+	case Prodigy:
+		return s.SaveProdigy(w)
+	case JSONL:
+		return s.SaveJSONL(w)
+	default:
+		return s.Save(w)
+	}
+}
+
+// builder regroups flat records into articles by title and paragraphs
+// by context keeping the order they first appear in.
+type builder struct {
+	squad      *SQuAD
+	articles   map[string]int
+	paragraphs map[[2]string]int
+}
+
+func newBuilder() *builder {
+	return &builder{
+		squad:      &SQuAD{Version: "1.1"},
+		articles:   make(map[string]int),
+		paragraphs: make(map[[2]string]int),
+	}
+}
+
+func (b *builder) add(title, context string, qa QA) {
+	a, ok := b.articles[title]
+	if !ok {
+		a = len(b.squad.Articles)
+		b.articles[title] = a
+		b.squad.Add(Article{Name: title})
+	}
+	article := b.squad.At(a)
+
+	key := [2]string{title, context}
+	p, ok := b.paragraphs[key]
+	if !ok {
+		p = len(article.Paragraphs)
+		b.paragraphs[key] = p
+		article.Add(Paragraph{Context: context})
+	}
+
+	if qa.Impossible {
+		b.squad.Version = "v2.0"
+	}
+
+	article.At(p).Add(qa)
+}
+
+// eachLine calls f for every non-blank line of JSON Lines input.
+func eachLine(r io.Reader, f func(line int, data []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		if err := f(line, scanner.Bytes()); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+
+	return scanner.Err()
+}
+
+func eachQA(s *SQuAD, f func(a Article, p Paragraph, qa QA) error) error {
+	for _, a := range s.Articles {
+		for _, p := range a.Paragraphs {
+			for _, qa := range p.QAs {
+				if err := f(a, p, qa); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func writeLine(w *bufio.Writer, v any) error {
+	if err := json.MarshalWrite(w, v); err != nil {
+		return err
+	}
+	return w.WriteByte('\n')
+}
+
+func skip(line int, reason string) Skipped {
+	return Skipped{Record: fmt.Sprintf("line %d", line), Reason: reason}
+}
+
+// inContext is Answer.IsIn safe for answers past the end of the context.
+func inContext(context []rune, a Answer) bool {
+	return a.Start >= 0 && a.To() <= len(context) && a.IsIn(context)
+}
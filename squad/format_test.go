@@ -0,0 +1,352 @@
+package squad_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/donderom/sqwat/squad"
+)
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, squad.JSON, squad.Detect([]byte(main)))
+	assert.Equal(t, squad.JSON, squad.Detect([]byte("")))
+	assert.Equal(t, squad.JSON, squad.Detect([]byte(`{"data": []}`)))
+	assert.Equal(t, squad.HuggingFace, squad.Detect([]byte(`{"id": "1", "title": "Go", "context": ""}`)))
+	assert.Equal(t, squad.JSONL, squad.Detect([]byte(`{"version": "v2.0"}\n{"title": "Go"}`)))
+}
+
+func TestHuggingFace(t *testing.T) {
+	t.Parallel()
+
+	t.Run("round trip", func(t *testing.T) {
+		t.Parallel()
+
+		var s strings.Builder
+		require.NoError(t, mainData().Write(&s, squad.HuggingFace))
+		assert.Equal(t, 4, strings.Count(s.String(), "\n"))
+
+		data, format, err := squad.Read(strings.NewReader(s.String()))
+		require.NoError(t, err)
+		assert.Equal(t, squad.HuggingFace, format)
+		assert.Equal(t, mainData().Articles, data.Articles)
+	})
+
+	t.Run("regroup", func(t *testing.T) {
+		t.Parallel()
+
+		rows := `{"id": "1", "title": "Go", "context": "Go is fun.", "question": "What is Go?", "answers": {"text": ["fun"], "answer_start": [6]}}
+{"id": "2", "title": "Rust", "context": "Rust is safe.", "question": "What is Rust?", "answers": {"text": ["safe"], "answer_start": [8]}}
+
+{"id": "3", "title": "Go", "context": "Go is fun.", "question": "Is Go boring?", "answers": {"text": [], "answer_start": []}}
+{"id": "4", "title": "Go", "context": "Go is simple.", "question": "Is Go simple?", "answers": {"text": ["simple"], "answer_start": [6]}}
+`
+		data, err := squad.LoadHuggingFace(strings.NewReader(rows))
+		require.NoError(t, err)
+
+		assert.Equal(t, "v2.0", data.Version)
+		require.Len(t, data.Articles, 2)
+		require.Len(t, data.Articles[0].Paragraphs, 2)
+
+		qas := data.Articles[0].Paragraphs[0].QAs
+		require.Len(t, qas, 2)
+		assert.False(t, qas[0].Impossible)
+		assert.True(t, qas[1].Impossible)
+	})
+
+	t.Run("fail on mismatched answers", func(t *testing.T) {
+		t.Parallel()
+
+		row := `{"id": "1", "answers": {"text": ["fun"], "answer_start": []}}`
+		_, err := squad.LoadHuggingFace(strings.NewReader(row))
+		assert.ErrorContains(t, err, "line 1")
+	})
+}
+
+func TestLabelStudio(t *testing.T) {
+	t.Parallel()
+
+	t.Run("round trip", func(t *testing.T) {
+		t.Parallel()
+
+		data := mainData()
+		data.At(1).At(0).Add(squad.QA{
+			Id:               "5",
+			Question:         "Is Rust 😀 slow?",
+			CorrectAnswers:   []squad.Answer{},
+			PlausibleAnswers: []squad.Answer{{Text: "performance", Start: 60}},
+			Impossible:       true,
+		})
+		data.At(0).At(1).Context = "It is 😀 syntactically similar to C"
+		data.At(0).At(1).At(0).CorrectAnswers[0].Start = 33
+
+		var s strings.Builder
+		require.NoError(t, data.Write(&s, squad.LabelStudio))
+		assert.Contains(t, s.String(), `"start": 34`)
+
+		loaded, format, err := squad.Read(strings.NewReader(s.String()))
+		require.NoError(t, err)
+		assert.Equal(t, squad.LabelStudio, format)
+		assert.Equal(t, data.Articles, loaded.Articles)
+	})
+
+	t.Run("annotations", func(t *testing.T) {
+		t.Parallel()
+
+		export := `[{
+			"id": 7,
+			"data": {
+				"title": "Go",
+				"context": "Go is fast and simple.",
+				"qas": [
+					{"id": "1", "question": "How is Go?", "is_impossible": false},
+					{"id": "2", "question": "Is Go slow?", "is_impossible": true}
+				]
+			},
+			"annotations": [
+				{"result": [], "was_cancelled": true},
+				{"result": [
+					{"id": "1_0", "from_name": "answer", "to_name": "context", "type": "labels",
+					 "value": {"start": 6, "end": 10, "text": "fast", "labels": ["Answer"]}},
+					{"id": "x8Yz", "from_name": "answer", "to_name": "context", "type": "labels",
+					 "value": {"start": 15, "end": 21, "text": "simple", "labels": ["Answer"]}},
+					{"id": "x8Yz", "from_name": "question", "to_name": "context", "type": "textarea",
+					 "value": {"text": ["Is Go simple?"]}}
+				]}
+			],
+			"predictions": []
+		}]`
+
+		data, err := squad.LoadLabelStudio(strings.NewReader(export))
+		require.NoError(t, err)
+		assert.Equal(t, "v2.0", data.Version)
+
+		qas := data.Articles[0].Paragraphs[0].QAs
+		require.Len(t, qas, 3)
+		assert.Equal(t, []squad.Answer{{Text: "fast", Start: 6}}, qas[0].CorrectAnswers)
+		assert.True(t, qas[1].Impossible)
+		assert.Equal(t, "Is Go simple?", qas[2].Question)
+		assert.False(t, qas[2].IsEmptyID())
+		assert.Equal(t, []squad.Answer{{Text: "simple", Start: 15}}, qas[2].CorrectAnswers)
+	})
+}
+
+func TestDoccano(t *testing.T) {
+	t.Parallel()
+
+	t.Run("round trip", func(t *testing.T) {
+		t.Parallel()
+
+		data := mainData()
+		data.At(1).At(0).Add(squad.QA{
+			Id:               "5",
+			Question:         "Is Rust slow?",
+			CorrectAnswers:   []squad.Answer{},
+			PlausibleAnswers: []squad.Answer{{Text: "performance", Start: 59}},
+			Impossible:       true,
+		})
+
+		var s strings.Builder
+		require.NoError(t, data.Write(&s, squad.Doccano))
+
+		loaded, format, err := squad.Read(strings.NewReader(s.String()))
+		require.NoError(t, err)
+		assert.Equal(t, squad.Doccano, format)
+		assert.Equal(t, data.Articles, loaded.Articles)
+	})
+
+	t.Run("unmapped", func(t *testing.T) {
+		t.Parallel()
+
+		records := `{"text": "Go is fast.", "label": [[6, 10, "ANSWER"]], "title": "Go", "question": "How is Go?", "qa_id": "1"}
+{"text": "Go is fast.", "label": [[6, 99, "ANSWER"]], "title": "Go", "question": "Is Go fast?"}
+{"text": "Go is fast.", "label": [], "title": "Go"}
+{"text": "Go is fast.", "label": [[0, 2, "LANGUAGE"]], "title": "Go", "question": "What is fast?"}
+`
+		data, err := squad.LoadDoccano(strings.NewReader(records))
+
+		var unmapped squad.Unmapped
+		require.ErrorAs(t, err, &unmapped)
+		require.Len(t, unmapped, 3)
+		assert.Equal(t, "line 2", unmapped[0].Record)
+
+		require.NotNil(t, data)
+		qas := data.Articles[0].Paragraphs[0].QAs
+		require.Len(t, qas, 1)
+		assert.Equal(t, []squad.Answer{{Text: "fast", Start: 6}}, qas[0].CorrectAnswers)
+	})
+
+	t.Run("write out of context", func(t *testing.T) {
+		t.Parallel()
+
+		data := mainData()
+		data.At(0).At(1).At(0).CorrectAnswers[0].Start = 99
+
+		var s strings.Builder
+		var unmapped squad.Unmapped
+		require.ErrorAs(t, data.Write(&s, squad.Doccano), &unmapped)
+		assert.Equal(t, squad.Unmapped{{Record: "3", Reason: "answer out of context"}}, unmapped)
+		assert.Equal(t, 3, strings.Count(s.String(), "\n"))
+	})
+}
+
+func TestProdigy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("round trip", func(t *testing.T) {
+		t.Parallel()
+
+		data := mainData()
+		data.At(1).At(0).Add(squad.QA{
+			Id:               "5",
+			Question:         "Is Rust slow?",
+			CorrectAnswers:   []squad.Answer{},
+			PlausibleAnswers: []squad.Answer{{Text: "performance", Start: 59}},
+			Impossible:       true,
+		})
+
+		var s strings.Builder
+		require.NoError(t, data.Write(&s, squad.Prodigy))
+		assert.Contains(t, s.String(), `"answer":"reject"`)
+
+		loaded, format, err := squad.Read(strings.NewReader(s.String()))
+		require.NoError(t, err)
+		assert.Equal(t, squad.Prodigy, format)
+		assert.Equal(t, data.Articles, loaded.Articles)
+	})
+
+	t.Run("unmapped", func(t *testing.T) {
+		t.Parallel()
+
+		records := `{"text": "Go is fast.", "spans": [{"start": 6, "end": 10, "label": "ANSWER"}], "answer": "accept", "meta": {"title": "Go", "question": "How is Go?", "id": "1"}}
+{"text": "Go is fast.", "spans": [], "answer": "ignore", "meta": {"title": "Go", "question": "Is Go slow?"}}
+{"text": "Go is fast.", "spans": [], "answer": "reject", "meta": {"title": "Go", "question": "Is Go slow?"}}
+`
+		data, err := squad.LoadProdigy(strings.NewReader(records))
+
+		var unmapped squad.Unmapped
+		require.ErrorAs(t, err, &unmapped)
+		assert.Equal(t, "line 2", unmapped[0].Record)
+
+		qas := data.Articles[0].Paragraphs[0].QAs
+		require.Len(t, qas, 2)
+		assert.True(t, qas[1].Impossible)
+	})
+}
+
+func TestGzip(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, squad.IsCompressed("train-v2.0.json.gz"))
+	assert.False(t, squad.IsCompressed("train-v2.0.json"))
+
+	for _, format := range []squad.Format{squad.JSON, squad.HuggingFace} {
+		t.Run(format.String(), func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			w := squad.Compress(&buf, "data.gz")
+			require.NoError(t, mainData().Write(w, format))
+			require.NoError(t, w.Close())
+
+			data, detected, err := squad.Read(bytes.NewReader(buf.Bytes()))
+			require.NoError(t, err)
+			assert.Equal(t, format, detected)
+			assert.Equal(t, mainData().Articles, data.Articles)
+		})
+	}
+
+	t.Run("load", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		w := squad.Compress(&buf, "data.json.gz")
+		require.NoError(t, mainData().Save(w))
+		require.NoError(t, w.Close())
+
+		data, err := squad.Load(&buf)
+		require.NoError(t, err)
+		assert.Equal(t, mainData().Articles, data.Articles)
+	})
+
+	t.Run("plain", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		w := squad.Compress(&buf, "data.json")
+		require.NoError(t, mainData().Save(w))
+		require.NoError(t, w.Close())
+		assert.Equal(t, byte('{'), buf.Bytes()[0])
+	})
+}
+
+func TestJSONL(t *testing.T) {
+	t.Parallel()
+
+	t.Run("round trip", func(t *testing.T) {
+		t.Parallel()
+
+		var s strings.Builder
+		require.NoError(t, mainData().Write(&s, squad.JSONL))
+
+		lines := strings.Split(strings.TrimSpace(s.String()), "\n")
+		require.Len(t, lines, len(mainData().Articles)+1)
+		assert.Equal(t, `{"version":"`+mainData().Version+`"}`, lines[0])
+
+		data, format, err := squad.Read(strings.NewReader(s.String()))
+		require.NoError(t, err)
+		assert.Equal(t, squad.JSONL, format)
+		assert.Equal(t, mainData(), data)
+	})
+
+	t.Run("no header", func(t *testing.T) {
+		t.Parallel()
+
+		input := `{"title":"Go","paragraphs":[{"context":"Go","qas":[{"id":"1","question":"?","answers":[],"is_impossible":true}]}]}` + "\n"
+		data, format, err := squad.Read(strings.NewReader(input))
+		require.NoError(t, err)
+		assert.Equal(t, squad.JSONL, format)
+		assert.Equal(t, "v2.0", data.Version)
+		require.Len(t, data.Articles, 1)
+		assert.Equal(t, "Go", data.Articles[0].Name)
+	})
+
+	t.Run("larger than detected", func(t *testing.T) {
+		t.Parallel()
+
+		long := mainData()
+		long.Articles[0].Name = strings.Repeat("Go ", 64*1024)
+		for _, f := range []squad.Format{squad.JSON, squad.JSONL} {
+			var s strings.Builder
+			require.NoError(t, long.Write(&s, f))
+
+			data, format, err := squad.Read(strings.NewReader(s.String()))
+			require.NoError(t, err)
+			assert.Equal(t, f, format)
+			assert.Equal(t, long, data)
+		}
+	})
+
+	t.Run("bad line", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := squad.LoadJSONL(strings.NewReader("{\"version\":\"1.1\"}\n{\"title\":\n"))
+		require.ErrorContains(t, err, "line 2")
+	})
+}
+
+func TestParseFormat(t *testing.T) {
+	t.Parallel()
+
+	format, ok := squad.ParseFormat("jsonl")
+	assert.True(t, ok)
+	assert.Equal(t, squad.JSONL, format)
+
+	_, ok = squad.ParseFormat("xml")
+	assert.False(t, ok)
+}
@@ -0,0 +1,43 @@
+package squad
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// IsCompressed tells by the extension whether filename is gzipped.
+func IsCompressed(filename string) bool {
+	return strings.EqualFold(filepath.Ext(filename), ".gz")
+}
+
+// Decompress reads r through gzip when it starts with the gzip header.
+func Decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(len(gzipMagic))
+	if err != nil || !bytes.Equal(head, gzipMagic) {
+		return br, nil
+	}
+
+	return gzip.NewReader(br)
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// Compress gzips the writes to w for a compressed filename. Closing the
+// returned writer flushes it but leaves w open.
+func Compress(w io.Writer, filename string) io.WriteCloser {
+	if IsCompressed(filename) {
+		return gzip.NewWriter(w)
+	}
+	return nopCloser{w}
+}
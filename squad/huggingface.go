@@ -0,0 +1,87 @@
+package squad
+
+import (
+	"bufio"
+	"encoding/json/v2"
+	"fmt"
+	"io"
+)
+
+type hfAnswers struct {
+	Text  []string `json:"text"`
+	Start []int    `json:"answer_start"`
+}
+
+type hfRow struct {
+	Id       string    `json:"id"`
+	Title    string    `json:"title"`
+	Context  string    `json:"context"`
+	Question string    `json:"question"`
+	Answers  hfAnswers `json:"answers"`
+}
+
+// LoadHuggingFace reads the flattened row-per-question JSON Lines layout
+// regrouping the rows into articles by title and paragraphs by context.
+// Questions without answers are impossible as in SQuAD 2.0.
+func LoadHuggingFace(r io.Reader) (*SQuAD, error) {
+	b := newBuilder()
+
+	err := eachLine(r, func(line int, data []byte) error {
+		var row hfRow
+		if err := json.Unmarshal(data, &row); err != nil {
+			return err
+		}
+
+		if len(row.Answers.Text) != len(row.Answers.Start) {
+			return fmt.Errorf("%d answers with %d starts", len(row.Answers.Text), len(row.Answers.Start))
+		}
+
+		qa := QA{
+			Id:             row.Id,
+			Question:       row.Question,
+			CorrectAnswers: []Answer{},
+			Impossible:     len(row.Answers.Text) == 0,
+		}
+		for i, text := range row.Answers.Text {
+			qa.CorrectAnswers = append(qa.CorrectAnswers, Answer{Text: text, Start: row.Answers.Start[i]})
+		}
+
+		b.add(row.Title, row.Context, qa)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return b.squad, nil
+}
+
+// SaveHuggingFace writes a row per question, plausible answers have
+// no place in the layout and are not written.
+func (s *SQuAD) SaveHuggingFace(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	err := eachQA(s, func(a Article, p Paragraph, qa QA) error {
+		row := hfRow{
+			Id:       qa.Id,
+			Title:    a.Name,
+			Context:  p.Context,
+			Question: qa.Question,
+			Answers:  hfAnswers{Text: []string{}, Start: []int{}},
+		}
+
+		if !qa.Impossible {
+			for _, answer := range qa.CorrectAnswers {
+				row.Answers.Text = append(row.Answers.Text, answer.Text)
+				row.Answers.Start = append(row.Answers.Start, answer.Start)
+			}
+		}
+
+		return writeLine(bw, row)
+	})
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,84 @@
+package squad
+
+import (
+	"bufio"
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"io"
+)
+
+type jsonlHeader struct {
+	Version string `json:"version"`
+}
+
+// LoadJSONL reads a dataset with an article per line. The first line may
+// be a header with the version, without it the version is 1.1 or v2.0
+// when there are impossible questions.
+func LoadJSONL(r io.Reader) (*SQuAD, error) {
+	s := &SQuAD{Articles: []Article{}}
+	versioned := false
+	first := true
+
+	err := eachLine(r, func(line int, data []byte) error {
+		if first {
+			first = false
+
+			var keys map[string]jsontext.Value
+			if err := json.Unmarshal(data, &keys); err != nil {
+				return err
+			}
+
+			if _, ok := keys["paragraphs"]; !ok {
+				if _, ok := keys["version"]; ok {
+					var header jsonlHeader
+					if err := json.Unmarshal(data, &header); err != nil {
+						return err
+					}
+					s.Version = header.Version
+					versioned = true
+					return nil
+				}
+			}
+		}
+
+		var article Article
+		if err := json.Unmarshal(data, &article); err != nil {
+			return err
+		}
+		s.Add(article)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !versioned {
+		s.Version = "1.1"
+		_ = eachQA(s, func(_ Article, _ Paragraph, qa QA) error {
+			if qa.Impossible {
+				s.Version = "v2.0"
+			}
+			return nil
+		})
+	}
+
+	return s, nil
+}
+
+// SaveJSONL writes the version header and then an article per line so
+// that changes to an article touch a single line.
+func (s *SQuAD) SaveJSONL(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	if err := writeLine(bw, jsonlHeader{Version: s.Version}); err != nil {
+		return err
+	}
+
+	for _, a := range s.Articles {
+		if err := writeLine(bw, a); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,310 @@
+package squad
+
+import (
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/donderom/sqwat/text"
+)
+
+// LabelStudioConfig is the labeling config matching the exported tasks.
+const LabelStudioConfig = `<View>
+  <Header value="$title"/>
+  <Text name="context" value="$context"/>
+  <Labels name="answer" toName="context">
+    <Label value="Answer"/>
+    <Label value="Plausible"/>
+  </Labels>
+  <TextArea name="question" toName="context" perRegion="true" editable="true" maxSubmissions="1"/>
+</View>
+`
+
+const (
+	lsAnswer    = "Answer"
+	lsPlausible = "Plausible"
+)
+
+type lsQA struct {
+	Id         string `json:"id"`
+	Question   string `json:"question"`
+	Impossible bool   `json:"is_impossible"`
+}
+
+// Task data keeps questions with their IDs, impossible ones included
+type lsData struct {
+	Title   string `json:"title"`
+	Context string `json:"context"`
+	QAs     []lsQA `json:"qas"`
+}
+
+type lsValue struct {
+	Start  int            `json:"start,omitzero"`
+	End    int            `json:"end,omitzero"`
+	Labels []string       `json:"labels,omitempty"`
+	Text   jsontext.Value `json:"text,omitzero"`
+}
+
+type lsResult struct {
+	Id       string  `json:"id"`
+	FromName string  `json:"from_name"`
+	ToName   string  `json:"to_name"`
+	Type     string  `json:"type"`
+	Value    lsValue `json:"value"`
+}
+
+type lsAnnotation struct {
+	Result       []lsResult `json:"result"`
+	ModelVersion string     `json:"model_version,omitempty"`
+	WasCancelled bool       `json:"was_cancelled,omitzero"`
+}
+
+type lsTask struct {
+	Data        lsData         `json:"data"`
+	Annotations []lsAnnotation `json:"annotations,omitempty"`
+	Predictions []lsAnnotation `json:"predictions,omitempty"`
+}
+
+// SaveLabelStudio writes a task per paragraph with the answers as
+// pre-annotated spans, the question of a span is a per-region text.
+// Offsets are in UTF-16 code units as Label Studio expects.
+func (s *SQuAD) SaveLabelStudio(w io.Writer) error {
+	tasks := make([]lsTask, 0)
+
+	for _, a := range s.Articles {
+		for _, p := range a.Paragraphs {
+			context := []rune(p.Context)
+			task := lsTask{Data: lsData{Title: a.Name, Context: p.Context, QAs: []lsQA{}}}
+			prediction := lsAnnotation{Result: []lsResult{}, ModelVersion: "sqwat"}
+
+			for _, qa := range p.QAs {
+				task.Data.QAs = append(task.Data.QAs, lsQA{
+					Id:         qa.Id,
+					Question:   qa.Question,
+					Impossible: qa.Impossible,
+				})
+
+				spans := func(label string, answers []Answer, offset int) error {
+					for i, answer := range answers {
+						id := fmt.Sprintf("%s_%d", qa.Id, offset+i)
+						results, err := lsRegion(id, label, qa.Question, answer, context)
+						if err != nil {
+							return err
+						}
+						prediction.Result = append(prediction.Result, results...)
+					}
+					return nil
+				}
+
+				if err := spans(lsAnswer, qa.CorrectAnswers, 0); err != nil {
+					return err
+				}
+				if err := spans(lsPlausible, qa.PlausibleAnswers, len(qa.CorrectAnswers)); err != nil {
+					return err
+				}
+			}
+
+			if len(prediction.Result) > 0 {
+				task.Predictions = []lsAnnotation{prediction}
+			}
+			tasks = append(tasks, task)
+		}
+	}
+
+	return json.MarshalWrite(w, tasks, jsontext.WithIndent("  "))
+}
+
+func lsRegion(id, label, question string, answer Answer, context []rune) ([]lsResult, error) {
+	answerText, err := json.Marshal(answer.Text)
+	if err != nil {
+		return nil, err
+	}
+
+	questionText, err := json.Marshal([]string{question})
+	if err != nil {
+		return nil, err
+	}
+
+	end := answer.Start + len([]rune(answer.Text))
+	return []lsResult{
+		{
+			Id:       id,
+			FromName: "answer",
+			ToName:   "context",
+			Type:     "labels",
+			Value: lsValue{
+				Start:  text.ToUTF16(context, answer.Start),
+				End:    text.ToUTF16(context, end),
+				Labels: []string{label},
+				Text:   answerText,
+			},
+		},
+		{
+			Id:       id,
+			FromName: "question",
+			ToName:   "context",
+			Type:     "textarea",
+			Value:    lsValue{Text: questionText},
+		},
+	}, nil
+}
+
+// LoadLabelStudio reads a Label Studio JSON export. The latest submitted
+// annotation of a task wins over the predictions. Spans are matched to
+// questions by region ID, then by question text, and become new
+// questions otherwise.
+func LoadLabelStudio(r io.Reader) (*SQuAD, error) {
+	var tasks []lsTask
+	if err := json.UnmarshalRead(r, &tasks); err != nil {
+		return nil, err
+	}
+
+	squad := &SQuAD{Version: "1.1"}
+	articles := make(map[string]int)
+
+	for n, task := range tasks {
+		p, err := lsParagraph(task)
+		if err != nil {
+			return nil, fmt.Errorf("task %d: %w", n+1, err)
+		}
+
+		for _, qa := range p.QAs {
+			if qa.Impossible {
+				squad.Version = "v2.0"
+			}
+		}
+
+		a, ok := articles[task.Data.Title]
+		if !ok {
+			a = len(squad.Articles)
+			articles[task.Data.Title] = a
+			squad.Add(Article{Name: task.Data.Title})
+		}
+		squad.At(a).Add(p)
+	}
+
+	return squad, nil
+}
+
+type lsRegionData struct {
+	label    string
+	question string
+	text     string
+	start    int
+	end      int
+	labeled  bool
+}
+
+func lsParagraph(task lsTask) (Paragraph, error) {
+	p := Paragraph{Context: task.Data.Context, QAs: []QA{}}
+	context := []rune(p.Context)
+
+	for _, q := range task.Data.QAs {
+		p.QAs = append(p.QAs, QA{
+			Id:             q.Id,
+			Question:       q.Question,
+			CorrectAnswers: []Answer{},
+			Impossible:     q.Impossible,
+		})
+	}
+
+	var regions []string
+	data := make(map[string]*lsRegionData)
+
+	for _, result := range lsResults(task) {
+		region, ok := data[result.Id]
+		if !ok {
+			region = &lsRegionData{}
+			data[result.Id] = region
+			regions = append(regions, result.Id)
+		}
+
+		switch result.Type {
+		case "labels":
+			region.labeled = true
+			region.start = text.FromUTF16(context, result.Value.Start)
+			region.end = text.FromUTF16(context, result.Value.End)
+			if len(result.Value.Labels) > 0 {
+				region.label = result.Value.Labels[0]
+			}
+
+			var span string
+			if err := json.Unmarshal(result.Value.Text, &span); err == nil {
+				region.text = span
+			} else {
+				region.text = string(context[region.start:max(region.start, region.end)])
+			}
+
+		case "textarea":
+			var lines []string
+			if err := json.Unmarshal(result.Value.Text, &lines); err != nil {
+				return p, err
+			}
+			region.question = strings.Join(lines, " ")
+		}
+	}
+
+	for _, id := range regions {
+		region := data[id]
+		if !region.labeled {
+			continue
+		}
+
+		i := lsFind(p.QAs, id, region.question)
+		if i == -1 {
+			qa := QA{Question: region.question, CorrectAnswers: []Answer{}}
+			qa.GenerateID()
+			p.QAs = append(p.QAs, qa)
+			i = len(p.QAs) - 1
+		}
+
+		qa := &p.QAs[i]
+		if region.question != "" {
+			qa.Question = region.question
+		}
+
+		answer := Answer{Text: region.text, Start: region.start}
+
+		if region.label == lsPlausible {
+			qa.PlausibleAnswers = append(qa.PlausibleAnswers, answer)
+		} else {
+			qa.CorrectAnswers = append(qa.CorrectAnswers, answer)
+			qa.Impossible = false
+		}
+	}
+
+	return p, nil
+}
+
+func lsResults(task lsTask) []lsResult {
+	for _, annotation := range slices.Backward(task.Annotations) {
+		if !annotation.WasCancelled {
+			return annotation.Result
+		}
+	}
+
+	if len(task.Predictions) > 0 {
+		return task.Predictions[0].Result
+	}
+
+	return nil
+}
+
+func lsFind(qas []QA, region, question string) int {
+	// Exported regions are named after the question ID
+	if n := strings.LastIndex(region, "_"); n != -1 {
+		id := region[:n]
+		if i := slices.IndexFunc(qas, func(qa QA) bool { return qa.Id == id }); i != -1 {
+			return i
+		}
+	}
+
+	if question == "" {
+		return -1
+	}
+
+	return slices.IndexFunc(qas, func(qa QA) bool { return qa.Question == question })
+}
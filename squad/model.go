@@ -56,6 +56,11 @@ var _ list.DefaultItem = Answer{}
 var _ text.Range = Answer{}
 
 func Load(r io.Reader) (*SQuAD, error) {
+	r, err := Decompress(r)
+	if err != nil {
+		return nil, err
+	}
+
 	var squad SQuAD
 	if err := json.UnmarshalRead(r, &squad); err != nil {
 		return nil, err
@@ -92,6 +97,26 @@ func (s *SQuAD) All() []Article {
 	return s.Articles
 }
 
+// Clone copies the dataset deep enough for edits of one not to show
+// in the other.
+func (s *SQuAD) Clone() *SQuAD {
+	c := &SQuAD{Version: s.Version, Articles: slices.Clone(s.Articles)}
+	for i := range c.Articles {
+		a := &c.Articles[i]
+		a.Paragraphs = slices.Clone(a.Paragraphs)
+		for j := range a.Paragraphs {
+			p := &a.Paragraphs[j]
+			p.QAs = slices.Clone(p.QAs)
+			for k := range p.QAs {
+				qa := &p.QAs[k]
+				qa.CorrectAnswers = slices.Clone(qa.CorrectAnswers)
+				qa.PlausibleAnswers = slices.Clone(qa.PlausibleAnswers)
+			}
+		}
+	}
+	return c
+}
+
 func (s *SQuAD) Save(w io.Writer) error {
 	jsonData, err := json.Marshal(s, jsontext.WithIndent("  "))
 	if err != nil {
@@ -147,6 +172,11 @@ func (a *Article) All() []Paragraph {
 	return a.Paragraphs
 }
 
+func (a Article) Equal(other Article) bool {
+	return a.Name == other.Name &&
+		slices.EqualFunc(a.Paragraphs, other.Paragraphs, Paragraph.Equal)
+}
+
 func (a Article) Title() string { return a.Name }
 
 func (a Article) Description() string {
@@ -196,6 +226,10 @@ func (p *Paragraph) Invert(index int) {
 	}
 }
 
+func (p Paragraph) Equal(other Paragraph) bool {
+	return p.Context == other.Context && slices.EqualFunc(p.QAs, other.QAs, QA.Equal)
+}
+
 func (p Paragraph) Title() string { return p.Context }
 
 func (p Paragraph) Description() string {
@@ -297,6 +331,14 @@ func (q QA) IsEmptyID() bool {
 	return strings.TrimSpace(q.Id) == ""
 }
 
+func (q QA) Equal(other QA) bool {
+	return q.Id == other.Id &&
+		q.Question == other.Question &&
+		q.Impossible == other.Impossible &&
+		slices.Equal(q.CorrectAnswers, other.CorrectAnswers) &&
+		slices.Equal(q.PlausibleAnswers, other.PlausibleAnswers)
+}
+
 func (q QA) Title() string { return q.Question }
 
 func (q QA) Description() string {
@@ -125,6 +125,21 @@ func TestSave(t *testing.T) {
 	)
 }
 
+func TestClone(t *testing.T) {
+	t.Parallel()
+
+	data := mainData()
+	clone := data.Clone()
+	assert.Equal(t, data, clone)
+
+	qa := &clone.At(0).At(0).QAs[0]
+	qa.Question = "Changed?"
+	qa.CorrectAnswers[0].Text = "changed"
+	clone.At(0).Name = "Changed"
+
+	assert.Equal(t, mainData(), data)
+}
+
 func TestArticle(t *testing.T) {
 	t.Parallel()
 
@@ -261,6 +276,21 @@ func TestAnswer(t *testing.T) {
 	assert.Equal(t, len(title)+8, answer.To())
 }
 
+func TestEqual(t *testing.T) {
+	t.Parallel()
+
+	data := mainData()
+	assert.True(t, data.Articles[0].Equal(mainData().Articles[0]))
+	assert.False(t, data.Articles[0].Equal(data.Articles[1]))
+
+	// Empty and missing answers are the same
+	qa := squad.QA{Id: "1", CorrectAnswers: []squad.Answer{}}
+	assert.True(t, qa.Equal(squad.QA{Id: "1"}))
+
+	data.At(0).At(0).At(0).CorrectAnswers[0].Start++
+	assert.False(t, data.Articles[0].Equal(mainData().Articles[0]))
+}
+
 func testQA(t *testing.T, qa squad.QA) {
 	t.Helper()
 
@@ -0,0 +1,147 @@
+package squad
+
+import (
+	"bufio"
+	"encoding/json/v2"
+	"fmt"
+	"io"
+)
+
+const (
+	prodigyAccept = "accept"
+	prodigyReject = "reject"
+)
+
+type prodigySpan struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Label string `json:"label"`
+	Text  string `json:"text,omitempty"`
+}
+
+type prodigyMeta struct {
+	Title    string `json:"title"`
+	Id       string `json:"id"`
+	Question string `json:"question"`
+}
+
+type prodigyRecord struct {
+	Text   string        `json:"text"`
+	Spans  []prodigySpan `json:"spans"`
+	Answer string        `json:"answer"`
+	Meta   prodigyMeta   `json:"meta"`
+}
+
+// LoadProdigy reads Prodigy span annotations with a record per question
+// kept in meta. Accepted records are possible questions, rejected ones
+// are impossible with their spans as plausible answers.
+func LoadProdigy(r io.Reader) (*SQuAD, error) {
+	b := newBuilder()
+	var unmapped Unmapped
+
+	err := eachLine(r, func(line int, data []byte) error {
+		var record prodigyRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			unmapped = append(unmapped, skip(line, err.Error()))
+			return nil
+		}
+
+		qa, reason := record.qa()
+		if reason != "" {
+			unmapped = append(unmapped, skip(line, reason))
+			return nil
+		}
+
+		b.add(record.Meta.Title, record.Text, qa)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return b.squad, unmapped.Err()
+}
+
+func (record prodigyRecord) qa() (QA, string) {
+	switch {
+	case record.Text == "":
+		return QA{}, "no text"
+	case record.Meta.Question == "":
+		return QA{}, "no question in meta"
+	case record.Answer != prodigyAccept && record.Answer != prodigyReject:
+		return QA{}, fmt.Sprintf("answer %q is neither accept nor reject", record.Answer)
+	}
+
+	qa := QA{
+		Id:             record.Meta.Id,
+		Question:       record.Meta.Question,
+		CorrectAnswers: []Answer{},
+		Impossible:     record.Answer == prodigyReject,
+	}
+	if qa.IsEmptyID() {
+		qa.GenerateID()
+	}
+
+	context := []rune(record.Text)
+	for _, span := range record.Spans {
+		if span.Start < 0 || span.End < span.Start || span.End > len(context) {
+			return QA{}, fmt.Sprintf("span [%d, %d] out of text", span.Start, span.End)
+		}
+
+		answer := Answer{Text: string(context[span.Start:span.End]), Start: span.Start}
+		if qa.Impossible {
+			qa.PlausibleAnswers = append(qa.PlausibleAnswers, answer)
+		} else {
+			qa.CorrectAnswers = append(qa.CorrectAnswers, answer)
+		}
+	}
+
+	return qa, ""
+}
+
+// SaveProdigy writes a record per question, impossible ones rejected.
+func (s *SQuAD) SaveProdigy(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	var unmapped Unmapped
+
+	err := eachQA(s, func(a Article, p Paragraph, qa QA) error {
+		record := prodigyRecord{
+			Text:   p.Context,
+			Spans:  []prodigySpan{},
+			Answer: prodigyAccept,
+			Meta:   prodigyMeta{Title: a.Name, Id: qa.Id, Question: qa.Question},
+		}
+
+		answers := qa.CorrectAnswers
+		if qa.Impossible {
+			record.Answer = prodigyReject
+			answers = qa.PlausibleAnswers
+		}
+
+		context := []rune(p.Context)
+		for _, answer := range answers {
+			if !inContext(context, answer) {
+				unmapped = append(unmapped, Skipped{Record: qa.Id, Reason: "answer out of context"})
+				return nil
+			}
+
+			record.Spans = append(record.Spans, prodigySpan{
+				Start: answer.From(),
+				End:   answer.To(),
+				Label: "ANSWER",
+				Text:  answer.Text,
+			})
+		}
+
+		return writeLine(bw, record)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = bw.Flush(); err != nil {
+		return err
+	}
+
+	return unmapped.Err()
+}
@@ -53,7 +53,7 @@ func NewStatus(
 
 	return status{
 		data:     data,
-		list:     teax.NewList(results, "Warnings", delegate),
+		list:     teax.Guard(teax.NewList(results, "Warnings", delegate), dataset),
 		results:  results,
 		dataset:  dataset,
 		filename: filename,
@@ -53,6 +53,9 @@ var (
 	Faint = lipgloss.NewStyle().Faint(true)
 	Alt   = lipgloss.NewStyle().Foreground(Palette.Blue)
 
+	Added   = lipgloss.NewStyle().Foreground(Palette.Green)
+	Removed = lipgloss.NewStyle().Foreground(Palette.Red).Strikethrough(true)
+
 	Border = borders{
 		Multi: border{
 			Style: newBorder("⋮"),
@@ -0,0 +1,297 @@
+package tabular
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+
+	"github.com/donderom/sqwat/squad"
+	"github.com/donderom/sqwat/text"
+)
+
+var (
+	ErrColumn  = errors.New("unknown column")
+	ErrMissing = errors.New("missing column")
+)
+
+// Columns maps the fields of a question onto header names.
+type Columns struct {
+	Title    string
+	Context  string
+	Question string
+	Answer   string
+	// Optional, generated when empty
+	Id string
+}
+
+var DefaultColumns = Columns{
+	Title:    "title",
+	Context:  "context",
+	Question: "question",
+	Answer:   "answer",
+	Id:       "id",
+}
+
+// ParseColumns overrides the default columns with field=header pairs.
+func ParseColumns(s string) (Columns, error) {
+	columns := DefaultColumns
+	if strings.TrimSpace(s) == "" {
+		return columns, nil
+	}
+
+	for pair := range strings.SplitSeq(s, ",") {
+		field, header, ok := strings.Cut(pair, "=")
+		if !ok {
+			return columns, fmt.Errorf("%w: %q", ErrColumn, pair)
+		}
+
+		header = strings.TrimSpace(header)
+		switch strings.TrimSpace(field) {
+		case "title":
+			columns.Title = header
+		case "context":
+			columns.Context = header
+		case "question":
+			columns.Question = header
+		case "answer":
+			columns.Answer = header
+		case "id":
+			columns.Id = header
+		default:
+			return columns, fmt.Errorf("%w: %q", ErrColumn, field)
+		}
+	}
+
+	return columns, nil
+}
+
+// Separator guesses the separator by the file extension.
+func Separator(filename string) rune {
+	if strings.EqualFold(filepath.Ext(filename), ".tsv") {
+		return '\t'
+	}
+	return ','
+}
+
+type Problem uint8
+
+const (
+	MissingAnswer Problem = iota
+	AmbiguousAnswer
+)
+
+func (p Problem) String() string {
+	switch p {
+	case MissingAnswer:
+		return "Answer not found in context"
+	case AmbiguousAnswer:
+		return "Answer found more than once"
+	}
+	return "Unknown"
+}
+
+// Review is a row that needs a decision before it can be imported.
+type Review struct {
+	Row     int
+	Problem Problem
+	Article string
+	Context string
+	QA      squad.QA
+	Answer  string
+	// Rune offsets of the answer in the context
+	Indices []int
+}
+
+var _ list.DefaultItem = Review{}
+
+func (r Review) Title() string { return r.QA.Question }
+
+func (r Review) Description() string {
+	return fmt.Sprintf("%s (row %d)", r.Problem, r.Row)
+}
+
+func (r Review) FilterValue() string { return r.QA.Question }
+
+// Answered is the reviewed question with the answer at start.
+func (r Review) Answered(start int) squad.QA {
+	qa := r.QA
+	qa.CorrectAnswers = []squad.Answer{{Text: r.Answer, Start: start}}
+	return qa
+}
+
+// Impossible is the reviewed question without an answer.
+func (r Review) Impossible() squad.QA {
+	qa := r.QA
+	qa.Impossible = true
+	return qa
+}
+
+// AddTo puts the reviewed question into s.
+func (r Review) AddTo(s *squad.SQuAD, qa squad.QA) {
+	add(s, r.Article, r.Context, qa)
+}
+
+type Result struct {
+	// Questions with exactly one answer match
+	Data    *squad.SQuAD
+	Reviews []Review
+	Rows    int
+}
+
+// Read maps the rows of a CSV or TSV file with a header onto questions.
+// Answer starts are computed from the context, rows where the answer
+// is missing or found several times are not guessed but reviewed.
+func Read(r io.Reader, separator rune, columns Columns) (Result, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = separator
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = separator == '\t'
+
+	header, err := reader.Read()
+	if err != nil {
+		return Result{}, err
+	}
+
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		index[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+
+	col := func(name string, required bool) (int, error) {
+		i, ok := index[strings.ToLower(name)]
+		if !ok {
+			if required {
+				return -1, fmt.Errorf("%w: %q", ErrMissing, name)
+			}
+			return -1, nil
+		}
+		return i, nil
+	}
+
+	var cols [5]int
+	for i, c := range []struct {
+		name     string
+		required bool
+	}{
+		{columns.Title, true},
+		{columns.Context, true},
+		{columns.Question, true},
+		{columns.Answer, true},
+		{columns.Id, false},
+	} {
+		if cols[i], err = col(c.name, c.required); err != nil {
+			return Result{}, err
+		}
+	}
+
+	result := Result{Data: &squad.SQuAD{Version: "v2.0"}}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return result, err
+		}
+		result.Rows++
+		row, _ := reader.FieldPos(0)
+
+		field := func(i int) string {
+			if i == -1 || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		qa := squad.QA{
+			Id:             field(cols[4]),
+			Question:       field(cols[2]),
+			CorrectAnswers: []squad.Answer{},
+		}
+		if qa.IsEmptyID() {
+			qa.GenerateID()
+		}
+
+		title, context, answer := field(cols[0]), field(cols[1]), field(cols[3])
+		indices := text.Indices(context, answer)
+
+		if len(indices) != 1 {
+			problem := MissingAnswer
+			if len(indices) > 1 {
+				problem = AmbiguousAnswer
+			}
+
+			result.Reviews = append(result.Reviews, Review{
+				Row:     row,
+				Problem: problem,
+				Article: title,
+				Context: context,
+				QA:      qa,
+				Answer:  answer,
+				Indices: indices,
+			})
+			continue
+		}
+
+		qa.CorrectAnswers = []squad.Answer{{Text: answer, Start: indices[0]}}
+		add(result.Data, title, context, qa)
+	}
+
+	return result, nil
+}
+
+// Append adds the questions of src to dst merging articles by title and
+// paragraphs by context. Questions with IDs already in dst get new ones.
+func Append(dst, src *squad.SQuAD) (renamed int) {
+	ids := make(map[string]struct{})
+	for _, a := range dst.Articles {
+		for _, p := range a.Paragraphs {
+			for _, qa := range p.QAs {
+				ids[qa.Id] = struct{}{}
+			}
+		}
+	}
+
+	for _, a := range src.Articles {
+		for _, p := range a.Paragraphs {
+			for _, qa := range p.QAs {
+				if _, ok := ids[qa.Id]; ok {
+					qa.GenerateID()
+					renamed++
+				}
+				ids[qa.Id] = struct{}{}
+				add(dst, a.Name, p.Context, qa)
+			}
+		}
+	}
+
+	return renamed
+}
+
+func add(s *squad.SQuAD, title, context string, qa squad.QA) {
+	a := slices.IndexFunc(s.Articles, func(a squad.Article) bool {
+		return a.Name == title
+	})
+	if a == -1 {
+		a = len(s.Articles)
+		s.Add(squad.Article{Name: title})
+	}
+	article := s.At(a)
+
+	p := slices.IndexFunc(article.Paragraphs, func(p squad.Paragraph) bool {
+		return p.Context == context
+	})
+	if p == -1 {
+		article.Add(squad.Paragraph{Context: context, QAs: []squad.QA{qa}})
+		return
+	}
+
+	article.At(p).Add(qa)
+}
@@ -0,0 +1,105 @@
+package tabular_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/donderom/sqwat/squad"
+	"github.com/donderom/sqwat/tabular"
+)
+
+const rows = `Topic,Passage,Question,Answer,Key
+Go,Go is fast. Go is simple.,What is Go?,simple,1
+Go,Go is fast. Go is simple.,Who is Go?,Go,2
+Go,Go is fast. Go is simple.,Is Go slow?,slow,3
+Rust,Rust is safe.,What is Rust?,safe,4
+`
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	columns, err := tabular.ParseColumns("title=Topic, context=Passage,id=Key")
+	require.NoError(t, err)
+
+	result, err := tabular.Read(strings.NewReader(rows), ',', columns)
+	require.NoError(t, err)
+	assert.Equal(t, 4, result.Rows)
+
+	require.Len(t, result.Data.Articles, 2)
+	qa := result.Data.Articles[0].Paragraphs[0].QAs[0]
+	assert.Equal(t, "1", qa.Id)
+	assert.Equal(t, []squad.Answer{{Text: "simple", Start: 18}}, qa.CorrectAnswers)
+
+	require.Len(t, result.Reviews, 2)
+	ambiguous, missing := result.Reviews[0], result.Reviews[1]
+
+	assert.Equal(t, tabular.AmbiguousAnswer, ambiguous.Problem)
+	assert.Equal(t, 3, ambiguous.Row)
+	assert.Equal(t, []int{0, 12}, ambiguous.Indices)
+	assert.Equal(t, tabular.MissingAnswer, missing.Problem)
+
+	ambiguous.AddTo(result.Data, ambiguous.Answered(12))
+	missing.AddTo(result.Data, missing.Impossible())
+
+	qas := result.Data.Articles[0].Paragraphs[0].QAs
+	require.Len(t, qas, 3)
+	assert.Equal(t, 12, qas[1].CorrectAnswers[0].Start)
+	assert.True(t, qas[2].Impossible)
+}
+
+func TestReadErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := tabular.ParseColumns("name=Topic")
+	require.ErrorIs(t, err, tabular.ErrColumn)
+
+	_, err = tabular.Read(strings.NewReader(rows), ',', tabular.DefaultColumns)
+	require.ErrorIs(t, err, tabular.ErrMissing)
+
+	_, err = tabular.Read(strings.NewReader(""), ',', tabular.DefaultColumns)
+	assert.Error(t, err)
+}
+
+func TestTSV(t *testing.T) {
+	t.Parallel()
+
+	tsv := "title\tcontext\tquestion\tanswer\nGo\tGo is \"fast\".\tHow is Go?\tfast\n"
+	result, err := tabular.Read(strings.NewReader(tsv), tabular.Separator("in.tsv"), tabular.DefaultColumns)
+	require.NoError(t, err)
+
+	qa := result.Data.Articles[0].Paragraphs[0].QAs[0]
+	assert.False(t, qa.IsEmptyID())
+	assert.Equal(t, 7, qa.CorrectAnswers[0].Start)
+}
+
+func TestAppend(t *testing.T) {
+	t.Parallel()
+
+	dst := &squad.SQuAD{Articles: []squad.Article{{
+		Name: "Go",
+		Paragraphs: []squad.Paragraph{{
+			Context: "Go is fast.",
+			QAs:     []squad.QA{{Id: "1", Question: "How is Go?"}},
+		}},
+	}}}
+	src := &squad.SQuAD{Articles: []squad.Article{
+		{Name: "Go", Paragraphs: []squad.Paragraph{{
+			Context: "Go is fast.",
+			QAs:     []squad.QA{{Id: "1", Question: "Is Go fast?"}},
+		}}},
+		{Name: "Rust", Paragraphs: []squad.Paragraph{{
+			Context: "Rust is safe.",
+			QAs:     []squad.QA{{Id: "2", Question: "Is Rust safe?"}},
+		}}},
+	}}
+
+	assert.Equal(t, 1, tabular.Append(dst, src))
+	require.Len(t, dst.Articles, 2)
+
+	qas := dst.Articles[0].Paragraphs[0].QAs
+	require.Len(t, qas, 2)
+	assert.NotEqual(t, "1", qas[1].Id)
+}
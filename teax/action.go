@@ -27,9 +27,39 @@ type RevertFunc[Item list.DefaultItem] func(
 	item Item,
 )
 
+// Change is what an action does to the collection.
+type Change uint8
+
+const (
+	Creation Change = iota
+	Modification
+	Deletion
+)
+
+// Inverse is the change that reverts this one.
+func (c Change) Inverse() Change {
+	switch c {
+	case Creation:
+		return Deletion
+	case Deletion:
+		return Creation
+	}
+	return c
+}
+
+// Edit is the change of the item at Index of Coll.
+type Edit struct {
+	Change Change
+	Coll   any
+	Index  int
+	// Before is the item in place of the edited one
+	Before any
+}
+
 type Action[Item list.DefaultItem] struct {
 	Apply  ApplyFunc[Item]
 	Revert RevertFunc[Item]
+	Change Change
 }
 
 type Actions[Item list.DefaultItem] struct {
@@ -82,7 +112,7 @@ func (_ Create[Item]) Revert(coll Collection[Item], index int, _ Item) {
 }
 
 func (a Create[Item]) toAction() Action[Item] {
-	return Action[Item]{Apply: a.Apply, Revert: a.Revert}
+	return Action[Item]{Apply: a.Apply, Revert: a.Revert, Change: Creation}
 }
 
 type Update[Item list.DefaultItem] struct{}
@@ -101,7 +131,7 @@ func (_ Update[Item]) Revert(coll Collection[Item], index int, item Item) {
 }
 
 func (a Update[Item]) toAction() Action[Item] {
-	return Action[Item]{Apply: a.Apply, Revert: a.Revert}
+	return Action[Item]{Apply: a.Apply, Revert: a.Revert, Change: Modification}
 }
 
 type Delete[Item list.DefaultItem] struct{}
@@ -120,7 +150,7 @@ func (_ Delete[Item]) Revert(coll Collection[Item], index int, item Item) {
 }
 
 func (a Delete[Item]) toAction() Action[Item] {
-	return Action[Item]{Apply: a.Apply, Revert: a.Revert}
+	return Action[Item]{Apply: a.Apply, Revert: a.Revert, Change: Deletion}
 }
 
 func DefaultActions[Item list.DefaultItem]() Actions[Item] {
@@ -99,3 +99,11 @@ func TestDeleteAction(t *testing.T) {
 	assert.Len(t, coll.items, 2)
 	assert.Equal(t, testItem, coll.Get(0))
 }
+
+func TestChangeInverse(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, teax.Deletion, teax.Creation.Inverse())
+	assert.Equal(t, teax.Creation, teax.Deletion.Inverse())
+	assert.Equal(t, teax.Modification, teax.Modification.Inverse())
+}
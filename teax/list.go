@@ -1,6 +1,7 @@
 package teax
 
 import (
+	"slices"
 	"time"
 
 	"github.com/donderom/sqwat/keyset"
@@ -16,9 +17,13 @@ const statusMessageLifetime = 5 * time.Second
 
 type model = list.Model
 
+const readOnlyMarker = " [read-only]"
+
 type List[T list.DefaultItem] struct {
 	model
 	keyHelp map[key.Help]struct{}
+	// Help of the bindings hidden in read-only mode
+	hidden map[key.Help]struct{}
 }
 
 func NewList[T list.DefaultItem](
@@ -63,6 +68,55 @@ func (m List[T]) Update(msg tea.Msg) (List[T], tea.Cmd) {
 	return m, cmd
 }
 
+// SetReadOnly marks the title and hides the mutating bindings along
+// with the extra ones from help.
+func (m *List[T]) SetReadOnly(extra ...key.Binding) {
+	m.Title += readOnlyMarker
+	m.hidden = make(map[key.Help]struct{})
+	for _, k := range slices.Concat(keyset.Mutating, extra) {
+		m.hidden[k.Help()] = struct{}{}
+	}
+}
+
+// Guard sets l read-only for a read-only dataset.
+func Guard[T list.DefaultItem](l List[T], dataset Dataset, extra ...key.Binding) List[T] {
+	if dataset.ReadOnly() {
+		l.SetReadOnly(extra...)
+	}
+	return l
+}
+
+func (m List[T]) ReadOnly() bool {
+	return m.hidden != nil
+}
+
+func (m List[T]) ShortHelp() []key.Binding {
+	return m.visible(m.model.ShortHelp())
+}
+
+func (m List[T]) FullHelp() [][]key.Binding {
+	groups := m.model.FullHelp()
+	visible := make([][]key.Binding, len(groups))
+	for i, group := range groups {
+		visible[i] = m.visible(group)
+	}
+	return visible
+}
+
+func (m List[T]) visible(bindings []key.Binding) []key.Binding {
+	if m.hidden == nil {
+		return bindings
+	}
+
+	visible := make([]key.Binding, 0, len(bindings))
+	for _, k := range bindings {
+		if _, ok := m.hidden[k.Help()]; !ok {
+			visible = append(visible, k)
+		}
+	}
+	return visible
+}
+
 func (m *List[T]) HighlightPattern(pattern string) {
 	m.Filter = list.UnsortedFilter
 	m.SetFilterText(pattern)
@@ -0,0 +1,40 @@
+package teax_test
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/donderom/sqwat/keyset"
+	"github.com/donderom/sqwat/teax"
+)
+
+func TestReadOnlyList(t *testing.T) {
+	t.Parallel()
+
+	keys := []key.Binding{keyset.View, keyset.Create, keyset.Edit, keyset.Delete, keyset.Status}
+	d := teax.Delegate[Item]{
+		Style:         teax.IdentityStyles[Item](),
+		ShortHelpKeys: keys,
+		FullHelpKeys:  keys,
+	}
+
+	list := teax.NewList([]Item{testItem}, "Articles", d)
+	assert.False(t, list.ReadOnly())
+	assert.Contains(t, list.ShortHelp(), keyset.Create)
+
+	list.SetReadOnly(keyset.Status)
+	assert.True(t, list.ReadOnly())
+	assert.Contains(t, list.Title, "read-only")
+
+	for _, k := range list.ShortHelp() {
+		assert.NotContains(t, []key.Help{keyset.Create.Help(), keyset.Edit.Help(), keyset.Delete.Help(), keyset.Status.Help()}, k.Help())
+	}
+	assert.Contains(t, list.ShortHelp(), keyset.View)
+
+	for _, group := range list.FullHelp() {
+		assert.NotContains(t, group, keyset.Delete)
+	}
+	assert.Equal(t, []key.Binding{keyset.View, keyset.Create, keyset.Edit, keyset.Delete, keyset.Status}, keys)
+}
@@ -2,6 +2,7 @@ package teax
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/donderom/sqwat/keyset"
@@ -15,16 +16,61 @@ import (
 
 const statusTimeout = time.Millisecond * 2000
 
+// Dataset is the data the screens browse and edit, what else it does
+// is up to the optional interfaces below it may implement.
 type Dataset interface {
+	ReadOnly() bool
+}
+
+// Saver saves the edits of a dataset.
+type Saver interface {
+	// Save saves the data at once
 	Save() error
+	// Schedule saves the data as it is now in the background, undo
+	// reverts the edit it's scheduled for if the save fails
+	Schedule(undo func()) tea.Cmd
+	// Scheduler tells how the scheduled saves go
+	Scheduler() *Scheduler
+	// Revert reverts the edits of a failed save, the app starts over
+	// from the returned screen
+	Revert() (tea.Model, error)
+	// Discard drops the edits not saved yet
+	Discard() error
+	// Pending shows the edits to be saved by the manual scheduler
+	Pending() tea.Model
+	// Modified resolves saves refused with ErrModified
+	Modified() tea.Model
+}
+
+// Journaler keeps track of the edits of a dataset.
+type Journaler interface {
+	// Journal records the edits before they're saved to recover them
+	// after a crash, either all of them or none, and logs them in the
+	// audit log. A Warning tells the edits are journaled but not logged.
+	Journal(edits ...Edit) error
+	// AuditLog browses the edits logged so far
+	AuditLog() tea.Model
+	// History browses the edits of the item at index of coll
+	History(coll any, index int) tea.Model
+	// ExportPatch writes the edits since the file was opened as a JSON
+	// Patch and tells where
+	ExportPatch() (string, error)
+}
+
+// Screens opens the screens about a dataset as a whole.
+type Screens interface {
 	Status(ctx context.Context) tea.Model
+	Duplicates() tea.Model
+	Import() tea.Model
 }
 
-type Synced[Item list.DefaultItem] struct {
-	Item   Item
-	Action Action[Item]
-	Err    error
-	Index  int
+// ErrModified is returned by Save when the file has been changed by
+// someone else since it was read, the changes are kept in memory.
+var ErrModified = errors.New("file changed on disk")
+
+// Error is shown by the screen opened after the one it happened on.
+type Error struct {
+	Err error
 }
 
 type Model[Item list.DefaultItem] struct {
@@ -36,11 +82,10 @@ type Model[Item list.DefaultItem] struct {
 	Dataset  Dataset
 	NewModel func(*Item) tea.Model
 	Parent   func() tea.Model
-	InSync   bool
 }
 
 func (m Model[Item]) Init() tea.Cmd {
-	if len(m.Coll.All()) == 0 {
+	if len(m.Coll.All()) == 0 && !m.Dataset.ReadOnly() {
 		mode, cmd := m.Form.Create(m.List.MaxDim())
 		return tea.Batch(cmd, SetMode(mode))
 	}
@@ -50,12 +95,13 @@ func (m Model[Item]) Init() tea.Cmd {
 func (m Model[Item]) Update(msg tea.Msg) (Model[Item], tea.Cmd) {
 	var cmd tea.Cmd
 
+	if saver, ok := m.Dataset.(Saver); ok && saver.Scheduler().Failed() {
+		model, err := saver.Revert()
+		return m, tea.Sequence(bubblon.ReplaceAll(model), bubblon.Cmd(Reverted{Err: err}))
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		if m.InSync {
-			return m, nil
-		}
-
 		if key.Matches(msg, keyset.Esc) {
 			if m.Mode == nil && m.List.Unfiltered() {
 				if m.Parent != nil {
@@ -98,19 +144,49 @@ func (m Model[Item]) Update(msg tea.Msg) (Model[Item], tea.Cmd) {
 			return m.Sync(m.Actions.Delete, index, backup)
 		}
 
-	case Synced[Item]:
+	case Saved:
+		if saver, ok := m.Dataset.(Saver); ok && errors.Is(msg.Err, ErrModified) {
+			return m, bubblon.Open(saver.Modified())
+		}
+		if IsWarning(msg.Err) {
+			return m, m.List.NewStatus(style.Error.Render(msg.Err.Error()))
+		}
 		if msg.Err != nil {
-			msg.Action.Revert(m.Coll, msg.Index, msg.Item)
-			m.InSync = false
-			return m, tea.Batch(
-				m.List.ToggleSpinner(),
-				m.List.NewStatus(style.Error.Render(msg.Err.Error())),
-			)
+			return m, m.List.NewStatus(style.Error.Render(
+				"Edits are not saved: " + msg.Err.Error(),
+			))
+		}
+
+	case Error:
+		return m, m.List.NewStatus(style.Error.Render(msg.Err.Error()))
+
+	case Reverted:
+		return m, m.List.NewStatus(style.Error.Render(
+			"Unsaved edits are reverted: " + msg.Err.Error(),
+		))
+
+	case saveAndQuit:
+		m.Mode = nil
+		saver, ok := m.Dataset.(Saver)
+		if !ok {
+			return m, tea.Quit
+		}
+		saves := saver.Scheduler()
+		return m, func() tea.Msg {
+			if err := saves.Flush(); err != nil {
+				return Saved{Err: err}
+			}
+			return tea.Quit()
 		}
 
-		m.List, cmd = msg.Action.Apply(m.List, m.Coll, msg.Index)
-		m.InSync = false
-		return m, tea.Batch(m.List.ToggleSpinner(), cmd)
+	case discardAndQuit:
+		m.Mode = nil
+		if saver, ok := m.Dataset.(Saver); ok {
+			if err := saver.Discard(); err != nil {
+				return m, m.List.NewStatus(style.Error.Render(err.Error()))
+			}
+		}
+		return m, tea.Quit
 
 	case bubblon.Closed:
 		if m.List.ItemSelected() {
@@ -130,10 +206,15 @@ func (m Model[Item]) Update(msg tea.Msg) (Model[Item], tea.Cmd) {
 			break
 		}
 
-		switch {
-		case key.Matches(msg, keyset.Quit):
-			return m, tea.Quit
+		if m.Dataset.ReadOnly() && key.Matches(msg, keyset.Mutating...) {
+			return m, nil
+		}
 
+		if cmd, ok := m.datasetKey(msg); ok {
+			return m, cmd
+		}
+
+		switch {
 		case key.Matches(msg, keyset.View):
 			if m.List.ItemSelected() {
 				return m, tea.Sequence(
@@ -158,16 +239,70 @@ func (m Model[Item]) Update(msg tea.Msg) (Model[Item], tea.Cmd) {
 				m.Mode = m.Form.Delete
 			}
 			return m, nil
+		}
+	}
+
+	m.List, cmd = m.List.Update(msg)
+	return m, cmd
+}
+
+// datasetKey handles the keys of what the dataset implements besides
+// Dataset, ok is false for the keys left to the list.
+func (m *Model[Item]) datasetKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	if saver, ok := m.Dataset.(Saver); ok {
+		saves := saver.Scheduler()
+		switch {
+		case key.Matches(msg, keyset.Quit):
+			if saves.Manual() && saves.Dirty() {
+				m.Mode = Unsaved{}
+				return nil, true
+			}
+			return tea.Quit, true
+
+		case key.Matches(msg, keyset.Save) && saves.Dirty():
+			if saves.Manual() {
+				return bubblon.Open(saver.Pending()), true
+			}
+			return func() tea.Msg {
+				return Saved{Err: saves.Flush()}
+			}, true
+		}
+	} else if key.Matches(msg, keyset.Quit) {
+		return tea.Quit, true
+	}
 
+	if screens, ok := m.Dataset.(Screens); ok {
+		switch {
 		case key.Matches(msg, keyset.Status):
 			ctx, cancel := context.WithTimeout(context.Background(), statusTimeout)
 			defer cancel()
-			return m, bubblon.Open(m.Dataset.Status(ctx))
+			return bubblon.Open(screens.Status(ctx)), true
+
+		case key.Matches(msg, keyset.Duplicates):
+			return bubblon.Open(screens.Duplicates()), true
 		}
 	}
 
-	m.List, cmd = m.List.Update(msg)
-	return m, cmd
+	if journaler, ok := m.Dataset.(Journaler); ok {
+		switch {
+		case key.Matches(msg, keyset.AuditLog):
+			return bubblon.Open(journaler.AuditLog()), true
+
+		case key.Matches(msg, keyset.ExportPatch):
+			path, err := journaler.ExportPatch()
+			if err != nil {
+				return m.List.NewStatus(style.Error.Render(err.Error())), true
+			}
+			return m.List.NewStatus("Edits exported to " + path), true
+
+		case key.Matches(msg, keyset.History):
+			if m.List.ItemSelected() {
+				return bubblon.Open(journaler.History(m.Coll, m.List.GlobalIndex())), true
+			}
+		}
+	}
+
+	return nil, false
 }
 
 func (m Model[Item]) View() string {
@@ -177,11 +312,18 @@ func (m Model[Item]) View() string {
 func (m Model[Item]) ListView() string {
 	mainStyle := style.Top.Render
 
-	if m.Mode != nil || m.InSync {
-		return mainStyle(style.Faint.Render(m.List.View()))
+	l := m.List
+	if saver, ok := m.Dataset.(Saver); ok {
+		if indicator := saver.Scheduler().View(); indicator != "" {
+			l.Title += " " + indicator
+		}
+	}
+
+	if m.Mode != nil {
+		return mainStyle(style.Faint.Render(l.View()))
 	}
 
-	return mainStyle(m.List.View())
+	return mainStyle(l.View())
 }
 
 func (m Model[Item]) HelpView() string {
@@ -191,7 +333,7 @@ func (m Model[Item]) HelpView() string {
 		return helpView(m.Mode.KeyMap())
 	}
 
-	if len(m.Coll.All()) == 0 {
+	if len(m.Coll.All()) == 0 && !m.Dataset.ReadOnly() {
 		if _, ok := m.List.keyHelp[keyset.Esc.Help()]; ok {
 			return helpView(keyset.Bindings(keyset.Create, keyset.Esc, keyset.Quit))
 		}
@@ -201,25 +343,36 @@ func (m Model[Item]) HelpView() string {
 	return helpView(m.List)
 }
 
+// Sync journals and audits the change of the item at index of the
+// collection and schedules it to be saved as far as the dataset does,
+// it's reverted when it can't be journaled.
 func (m Model[Item]) Sync(
 	action Action[Item],
 	index int,
 	item Item,
 ) (Model[Item], tea.Cmd) {
+	var cmd tea.Cmd
 	m.Mode = nil
-	m.InSync = true
-
-	return m, tea.Batch(
-		m.List.StartSpinner(),
-		func() tea.Msg {
-			return Synced[Item]{
-				Action: action,
-				Index:  index,
-				Item:   item,
-				Err:    m.Dataset.Save(),
-			}
-		},
-	)
+
+	var err error
+	if journaler, ok := m.Dataset.(Journaler); ok {
+		err = journaler.Journal(Edit{Change: action.Change, Coll: m.Coll, Index: index, Before: item})
+		if err != nil && !IsWarning(err) {
+			action.Revert(m.Coll, index, item)
+			return m, m.List.NewStatus(style.Error.Render(err.Error()))
+		}
+	}
+
+	m.List, cmd = action.Apply(m.List, m.Coll, index)
+	if err != nil {
+		cmd = tea.Batch(cmd, m.List.NewStatus(style.Error.Render(err.Error())))
+	}
+	if saver, ok := m.Dataset.(Saver); ok {
+		cmd = tea.Batch(cmd, saver.Schedule(func() {
+			action.Revert(m.Coll, index, item)
+		}))
+	}
+	return m, cmd
 }
 
 func (m *Model[Item]) Resize(msg tea.WindowSizeMsg) {
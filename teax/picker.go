@@ -0,0 +1,153 @@
+package teax
+
+import (
+	"github.com/charmbracelet/bubbles/filepicker"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/donderom/bubblon"
+
+	"github.com/donderom/sqwat/keyset"
+	"github.com/donderom/sqwat/style"
+)
+
+var pickerTitle = lipgloss.NewStyle().
+	MarginBottom(1).
+	MarginTop(1).
+	MarginLeft(2)
+
+// PickFailed is sent by the open function when the file can't be used.
+type PickFailed struct {
+	Err error
+}
+
+type Picker struct {
+	filepicker filepicker.Model
+	help       help.Model
+	height     int
+	title      string
+	open       func(path string) tea.Cmd
+	closable   bool
+	err        error
+}
+
+var _ tea.Model = Picker{}
+
+func NewPicker(
+	title string,
+	path string,
+	types []string,
+	open func(path string) tea.Cmd,
+) Picker {
+	fp := filepicker.New()
+	fp.AllowedTypes = types
+	fp.CurrentDirectory = path
+
+	return Picker{
+		filepicker: fp,
+		help:       help.New(),
+		title:      title,
+		open:       open,
+	}
+}
+
+// Closable makes esc close the picker, it's the parent directory otherwise.
+func (m Picker) Closable() Picker {
+	m.closable = true
+	return m
+}
+
+func (m Picker) Init() tea.Cmd {
+	return m.filepicker.Init()
+}
+
+func (m Picker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case PickFailed:
+		m.err = msg.Err
+		return m, nil
+
+	case tea.KeyMsg:
+		m.err = nil
+
+		if key.Matches(msg, keyset.Quit) {
+			return m, tea.Quit
+		}
+
+		if m.closable && key.Matches(msg, keyset.Esc) {
+			return m, bubblon.Close
+		}
+
+		if key.Matches(msg, keyset.More) {
+			m.help.ShowAll = !m.help.ShowAll
+		}
+
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+	}
+
+	var cmd tea.Cmd
+	m.filepicker, cmd = m.filepicker.Update(msg)
+
+	if selected, path := m.filepicker.DidSelectFile(msg); selected {
+		return m, m.open(path)
+	}
+
+	return m, cmd
+}
+
+func (m Picker) View() string {
+	if m.help.ShowAll {
+		m.filepicker.SetHeight(m.height - len(m.FullHelp()[0]) - 4)
+	}
+
+	title := m.title
+	if m.err != nil {
+		title = style.Error.Render(m.err.Error())
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		pickerTitle.Render(title),
+		m.filepicker.View(),
+		style.Bot.Render(m.HelpView()),
+	)
+}
+
+func (m Picker) ShortHelp() []key.Binding {
+	bindings := []key.Binding{
+		m.filepicker.KeyMap.Down,
+		m.filepicker.KeyMap.Up,
+		m.filepicker.KeyMap.Back,
+		m.filepicker.KeyMap.Open,
+		m.filepicker.KeyMap.Select,
+	}
+
+	if m.closable {
+		bindings = append(bindings, keyset.Esc)
+	}
+
+	return append(bindings, keyset.More)
+}
+
+func (m Picker) FullHelp() [][]key.Binding {
+	short := m.ShortHelp()
+
+	return [][]key.Binding{
+		short[:len(short)-1],
+		{
+			m.filepicker.KeyMap.GoToTop,
+			m.filepicker.KeyMap.GoToLast,
+			m.filepicker.KeyMap.PageUp,
+			m.filepicker.KeyMap.PageDown,
+		},
+		{
+			keyset.CloseMore,
+		},
+	}
+}
+
+func (m Picker) HelpView() string {
+	return m.help.View(m)
+}
@@ -0,0 +1,263 @@
+package teax
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/donderom/sqwat/style"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Saved is sent once a scheduled save is over.
+type Saved struct {
+	Err error
+}
+
+// Reverted is sent to the screen opened after the edits of a failed
+// save are reverted.
+type Reverted struct {
+	Err error
+}
+
+// Warning is returned by a save that went through with something to
+// tell, the edits count as saved.
+type Warning struct {
+	Err error
+}
+
+func (w Warning) Error() string {
+	return w.Err.Error()
+}
+
+func (w Warning) Unwrap() error {
+	return w.Err
+}
+
+// IsWarning tells whether err is a Warning of a save that went through.
+func IsWarning(err error) bool {
+	_, ok := errors.AsType[Warning](err)
+	return ok
+}
+
+type saveState uint8
+
+const (
+	clean saveState = iota
+	dirty
+	saving
+	saved
+	failed
+)
+
+// Scheduler saves the edits made in quick succession at once in the
+// background. Each edit schedules a save of the data as it is then,
+// only the last one is saved when no other follows within the delay.
+// Manual ones wait for the edits to be flushed instead.
+//
+// The edits since the last save that went through are reverted once a
+// save fails, the screens take that up with Revert.
+type Scheduler struct {
+	delay  time.Duration
+	manual bool
+	// One save at a time, scheduled or not
+	write sync.Mutex
+
+	mu    sync.Mutex
+	gen   int
+	save  func() error
+	state saveState
+	err   error
+	// Reverts of the edits not saved yet by the gen of their save
+	reverts []revert
+}
+
+type revert struct {
+	gen    int
+	revert func()
+}
+
+func NewScheduler(delay time.Duration) *Scheduler {
+	return &Scheduler{delay: delay}
+}
+
+func NewManualScheduler() *Scheduler {
+	return &Scheduler{manual: true}
+}
+
+func (s *Scheduler) Manual() bool {
+	return s.manual
+}
+
+// Schedule saves with save in place of the one scheduled before,
+// the edit it's scheduled for is reverted with undo if it fails.
+func (s *Scheduler) Schedule(save func() error, undo func()) tea.Cmd {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.gen++
+	s.save = save
+	s.state = dirty
+	if undo != nil {
+		s.reverts = append(s.reverts, revert{gen: s.gen, revert: undo})
+	}
+
+	gen := s.gen
+	if s.manual {
+		return nil
+	}
+	if s.delay == 0 {
+		return func() tea.Msg { return s.flush(gen) }
+	}
+	return tea.Tick(s.delay, func(time.Time) tea.Msg { return s.flush(gen) })
+}
+
+func (s *Scheduler) flush(gen int) tea.Msg {
+	s.write.Lock()
+	defer s.write.Unlock()
+
+	s.mu.Lock()
+	if gen != s.gen || s.save == nil {
+		s.mu.Unlock()
+		return nil
+	}
+	save := s.save
+	s.state = saving
+	s.mu.Unlock()
+
+	err := save()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settle(gen, err)
+	return Saved{Err: err}
+}
+
+// Save runs save at once in place of the scheduled one.
+func (s *Scheduler) Save(save func() error) error {
+	s.write.Lock()
+	defer s.write.Unlock()
+
+	s.mu.Lock()
+	s.gen++
+	s.state = saving
+	gen := s.gen
+	s.mu.Unlock()
+
+	err := save()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settle(gen, err)
+	return err
+}
+
+// Flush runs the scheduled save without waiting for the delay.
+func (s *Scheduler) Flush() error {
+	s.mu.Lock()
+	gen := s.gen
+	s.mu.Unlock()
+
+	if saved, ok := s.flush(gen).(Saved); ok {
+		return saved.Err
+	}
+	return nil
+}
+
+func (s *Scheduler) settle(gen int, err error) {
+	if IsWarning(err) {
+		err = nil
+	}
+	s.err = err
+	if err == nil {
+		// The edits saved are not to be reverted anymore
+		s.reverts = slices.DeleteFunc(s.reverts, func(r revert) bool { return r.gen <= gen })
+	}
+
+	switch {
+	case err == nil && gen == s.gen:
+		s.save = nil
+		s.state = saved
+	case err == nil:
+		// Edits made while saving are still to be saved
+		s.state = dirty
+	case errors.Is(err, ErrModified) || s.manual:
+		// The edits are kept in memory until the file is resolved or
+		// saved again
+		s.state = dirty
+	default:
+		// The edits are to be reverted
+		s.state = failed
+	}
+}
+
+// Dirty tells whether there are edits not saved yet.
+func (s *Scheduler) Dirty() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.save != nil
+}
+
+// Discard drops the scheduled save.
+func (s *Scheduler) Discard() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.gen++
+	s.save = nil
+	s.state = clean
+	s.err = nil
+	s.reverts = nil
+}
+
+// Failed tells whether the last save failed, its edits are to be
+// reverted.
+func (s *Scheduler) Failed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state == failed
+}
+
+// Revert reverts the edits since the last save that went through,
+// the newest first, and tells why the save failed.
+func (s *Scheduler) Revert() error {
+	s.mu.Lock()
+	reverts, err := s.reverts, s.err
+	s.gen++
+	s.save = nil
+	s.state = clean
+	s.err = nil
+	s.reverts = nil
+	s.mu.Unlock()
+
+	for _, r := range slices.Backward(reverts) {
+		r.revert()
+	}
+	return err
+}
+
+// View is the indicator of the edits not saved yet.
+func (s *Scheduler) View() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch s.state {
+	case dirty:
+		if errors.Is(s.err, ErrModified) {
+			return style.Error.Render("● unsaved, file changed on disk")
+		}
+		if s.manual {
+			return style.Highlight.Render("● unsaved · ctrl+s to save")
+		}
+		return style.Highlight.Render("● unsaved")
+	case saving:
+		return style.Faint.Render("saving...")
+	case saved:
+		return style.Faint.Render("saved")
+	case failed:
+		return style.Error.Render("save failed")
+	}
+	return ""
+}
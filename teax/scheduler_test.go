@@ -0,0 +1,104 @@
+package teax_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/donderom/sqwat/teax"
+)
+
+func TestSchedulerCoalesces(t *testing.T) {
+	t.Parallel()
+
+	var saved []int
+	s := teax.NewScheduler(time.Millisecond)
+	first := s.Schedule(func() error { saved = append(saved, 1); return nil }, nil)
+	second := s.Schedule(func() error { saved = append(saved, 2); return nil }, nil)
+	assert.Contains(t, s.View(), "unsaved")
+
+	assert.Nil(t, first())
+	assert.Equal(t, teax.Saved{}, second())
+	assert.Equal(t, []int{2}, saved)
+	assert.NotContains(t, s.View(), "unsaved")
+	assert.Contains(t, s.View(), "saved")
+	assert.False(t, s.Failed())
+}
+
+func TestSchedulerFailed(t *testing.T) {
+	t.Parallel()
+
+	errFull := errors.New("disk full")
+	var reverted []int
+	s := teax.NewScheduler(time.Hour)
+	assert.NoError(t, s.Save(func() error { return nil }))
+	_ = s.Schedule(func() error { return nil }, func() { reverted = append(reverted, 1) })
+	assert.NoError(t, s.Flush())
+
+	_ = s.Schedule(func() error { return errFull }, func() { reverted = append(reverted, 2) })
+	_ = s.Schedule(func() error { return errFull }, func() { reverted = append(reverted, 3) })
+	assert.Equal(t, errFull, s.Flush())
+	assert.Contains(t, s.View(), "save failed")
+	assert.True(t, s.Failed())
+
+	// Only the edits since the last save that went through, newest first
+	assert.Equal(t, errFull, s.Revert())
+	assert.Equal(t, []int{3, 2}, reverted)
+	assert.False(t, s.Failed())
+	assert.False(t, s.Dirty())
+	assert.Empty(t, s.View())
+}
+
+func TestSchedulerModified(t *testing.T) {
+	t.Parallel()
+
+	s := teax.NewScheduler(0)
+	cmd := s.Schedule(func() error { return teax.ErrModified }, nil)
+
+	assert.Equal(t, teax.Saved{Err: teax.ErrModified}, cmd())
+	assert.Contains(t, s.View(), "changed on disk")
+	assert.False(t, s.Failed())
+}
+
+func TestSchedulerFlush(t *testing.T) {
+	t.Parallel()
+
+	saves := 0
+	s := teax.NewScheduler(time.Hour)
+	_ = s.Schedule(func() error { saves++; return nil }, nil)
+
+	assert.NoError(t, s.Flush())
+	assert.NoError(t, s.Flush())
+	assert.Equal(t, 1, saves)
+
+	assert.NoError(t, s.Save(func() error { saves++; return nil }))
+	assert.Equal(t, 2, saves)
+}
+
+func TestSchedulerManual(t *testing.T) {
+	t.Parallel()
+
+	errFull := errors.New("disk full")
+	saves := 0
+	s := teax.NewManualScheduler()
+	assert.Nil(t, s.Schedule(func() error { saves++; return errFull }, nil))
+	assert.True(t, s.Dirty())
+	assert.Contains(t, s.View(), "ctrl+s")
+
+	assert.Equal(t, errFull, s.Flush())
+	assert.True(t, s.Dirty())
+	assert.False(t, s.Failed())
+
+	_ = s.Schedule(func() error { saves++; return nil }, nil)
+	assert.NoError(t, s.Flush())
+	assert.False(t, s.Dirty())
+	assert.Equal(t, 2, saves)
+
+	_ = s.Schedule(func() error { saves++; return nil }, nil)
+	s.Discard()
+	assert.False(t, s.Dirty())
+	assert.NoError(t, s.Flush())
+	assert.Equal(t, 2, saves)
+}
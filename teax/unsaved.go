@@ -0,0 +1,46 @@
+package teax
+
+import (
+	"github.com/donderom/sqwat/keyset"
+	"github.com/donderom/sqwat/style"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type saveAndQuit struct{}
+
+type discardAndQuit struct{}
+
+// Unsaved asks what to do with the edits not saved yet on quit.
+type Unsaved struct{}
+
+func (u Unsaved) Update(msg tea.Msg) (Mode, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keyset.Save):
+			return u, func() tea.Msg { return saveAndQuit{} }
+		case key.Matches(msg, keyset.Discard):
+			return u, func() tea.Msg { return discardAndQuit{} }
+		}
+	}
+	return u, nil
+}
+
+func (u Unsaved) View() string {
+	return style.Mid.Inherit(style.Highlight).Render("Save the edits before quitting?")
+}
+
+func (u Unsaved) Height() int {
+	return 1 + style.Mid.GetVerticalFrameSize()
+}
+
+func (u Unsaved) KeyMap() help.KeyMap {
+	return keyset.KeyMaps.Unsaved
+}
+
+func (u Unsaved) Resize(width, height int) Mode {
+	return u
+}
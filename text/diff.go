@@ -0,0 +1,104 @@
+package text
+
+import (
+	"strings"
+	"unicode"
+)
+
+type Op uint8
+
+const (
+	Equal Op = iota
+	Insert
+	Delete
+)
+
+type Edit struct {
+	Op   Op
+	Text string
+}
+
+// Diff returns word-level edits turning a into b.
+func Diff(a, b string) []Edit {
+	x, y := pieces(a), pieces(b)
+	n, m := len(x), len(y)
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:] and y[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var edits []Edit
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case x[i] == y[j]:
+			edits = appendEdit(edits, Equal, x[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			edits = appendEdit(edits, Delete, x[i])
+			i++
+		default:
+			edits = appendEdit(edits, Insert, y[j])
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		edits = appendEdit(edits, Delete, x[i])
+	}
+	for ; j < m; j++ {
+		edits = appendEdit(edits, Insert, y[j])
+	}
+
+	return edits
+}
+
+func appendEdit(edits []Edit, op Op, s string) []Edit {
+	if n := len(edits); n > 0 && edits[n-1].Op == op {
+		edits[n-1].Text += s
+		return edits
+	}
+	return append(edits, Edit{Op: op, Text: s})
+}
+
+// pieces splits s into words, punctuation marks and whitespace runs
+// so that joining them gives s back.
+func pieces(s string) []string {
+	var result []string
+	var b strings.Builder
+	kind := -1
+
+	for _, r := range s {
+		k := 0
+		switch {
+		case unicode.IsSpace(r):
+			k = 1
+		case !isWord(r):
+			k = 2
+		}
+
+		if b.Len() > 0 && (k != kind || k == 2) {
+			result = append(result, b.String())
+			b.Reset()
+		}
+		b.WriteRune(r)
+		kind = k
+	}
+
+	if b.Len() > 0 {
+		result = append(result, b.String())
+	}
+
+	return result
+}
@@ -0,0 +1,26 @@
+package text
+
+import "unicode/utf16"
+
+// ToUTF16 converts a rune offset into s to an offset in UTF-16 code
+// units as used by JavaScript tools.
+func ToUTF16(s []rune, offset int) int {
+	units := 0
+	for _, r := range s[:min(offset, len(s))] {
+		units += utf16.RuneLen(r)
+	}
+	return units
+}
+
+// FromUTF16 converts an offset in UTF-16 code units into s to a rune
+// offset, offsets in the middle of a surrogate pair round up.
+func FromUTF16(s []rune, offset int) int {
+	units := 0
+	for i, r := range s {
+		if units >= offset {
+			return i
+		}
+		units += utf16.RuneLen(r)
+	}
+	return len(s)
+}
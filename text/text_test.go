@@ -139,3 +139,110 @@ func TestIndices(t *testing.T) {
 		})
 	}
 }
+
+func TestTokenize(t *testing.T) {
+	t.Parallel()
+
+	tokens := text.Tokenize("Go's  speed, über-fast!")
+	expected := []text.Token{
+		{Text: "Go", Start: 0, End: 2},
+		{Text: "'", Start: 2, End: 3},
+		{Text: "s", Start: 3, End: 4},
+		{Text: "speed", Start: 6, End: 11},
+		{Text: ",", Start: 11, End: 12},
+		{Text: "über", Start: 13, End: 17},
+		{Text: "-", Start: 17, End: 18},
+		{Text: "fast", Start: 18, End: 22},
+		{Text: "!", Start: 22, End: 23},
+	}
+	assert.Equal(t, expected, tokens)
+
+	context := []rune("Go's  speed, über-fast!")
+	for _, token := range tokens {
+		assert.True(t, token.IsIn(context))
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	t.Parallel()
+
+	assert.InDelta(t, 1.0, text.Similarity("", ""), 0)
+	assert.InDelta(t, 1.0, text.Similarity("Go is fast", "go, is FAST"), 0)
+	assert.InDelta(t, 0.5, text.Similarity("Go is fast", "Go is slow"), 0)
+	assert.InDelta(t, 0.0, text.Similarity("Go", "Rust"), 0)
+}
+
+func TestShingles(t *testing.T) {
+	t.Parallel()
+
+	assert.Len(t, text.Shingles("a b c d", 2), 3)
+	assert.Len(t, text.Shingles("a b", 3), 1)
+	assert.Empty(t, text.Shingles("", 3))
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected []text.Edit
+	}{
+		{
+			name:     "equal",
+			a:        "What is Go?",
+			b:        "What is Go?",
+			expected: []text.Edit{{Op: text.Equal, Text: "What is Go?"}},
+		},
+		{
+			name: "replaced word",
+			a:    "What is Go?",
+			b:    "What was Go?",
+			expected: []text.Edit{
+				{Op: text.Equal, Text: "What "},
+				{Op: text.Delete, Text: "is"},
+				{Op: text.Insert, Text: "was"},
+				{Op: text.Equal, Text: " Go?"},
+			},
+		},
+		{
+			name: "inserted words",
+			a:    "Go",
+			b:    "Go is fast",
+			expected: []text.Edit{
+				{Op: text.Equal, Text: "Go"},
+				{Op: text.Insert, Text: " is fast"},
+			},
+		},
+		{
+			name:     "empty",
+			a:        "",
+			b:        "",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, text.Diff(tt.a, tt.b))
+		})
+	}
+}
+
+func TestUTF16(t *testing.T) {
+	t.Parallel()
+
+	s := []rune("a😀b€c")
+	assert.Equal(t, 0, text.ToUTF16(s, 0))
+	assert.Equal(t, 3, text.ToUTF16(s, 2))
+	assert.Equal(t, 6, text.ToUTF16(s, 5))
+	assert.Equal(t, 6, text.ToUTF16(s, 10))
+
+	for i := range len(s) + 1 {
+		assert.Equal(t, i, text.FromUTF16(s, text.ToUTF16(s, i)))
+	}
+	assert.Equal(t, 2, text.FromUTF16(s, 2))
+}
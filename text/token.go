@@ -0,0 +1,105 @@
+package text
+
+import (
+	"strings"
+	"unicode"
+)
+
+type Token struct {
+	Text  string
+	Start int
+	End   int
+}
+
+func (t Token) From() int { return t.Start }
+func (t Token) To() int   { return t.End }
+func (t Token) IsIn(context []rune) bool {
+	return t.End <= len(context) && string(context[t.Start:t.End]) == t.Text
+}
+
+var _ Range = Token{}
+
+// Tokenize splits s into words and single punctuation marks skipping
+// whitespace. Offsets are in runes to match the answer offsets.
+func Tokenize(s string) []Token {
+	var tokens []Token
+	runes := []rune(s)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case isWord(r):
+			start := i
+			for i < len(runes) && isWord(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, Token{
+				Text:  string(runes[start:i]),
+				Start: start,
+				End:   i,
+			})
+
+		default:
+			tokens = append(tokens, Token{Text: string(r), Start: i, End: i + 1})
+			i++
+		}
+	}
+
+	return tokens
+}
+
+func Words(s string) []string {
+	words := strings.FieldsFunc(s, func(r rune) bool { return !isWord(r) })
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	return words
+}
+
+// Shingles returns the set of lowercased word n-grams of s.
+// Texts shorter than n words produce a single shingle.
+func Shingles(s string, n int) map[string]struct{} {
+	words := Words(s)
+	shingles := make(map[string]struct{})
+
+	if len(words) == 0 {
+		return shingles
+	}
+
+	if len(words) < n {
+		shingles[strings.Join(words, " ")] = struct{}{}
+		return shingles
+	}
+
+	for i := 0; i+n <= len(words); i++ {
+		shingles[strings.Join(words[i:i+n], " ")] = struct{}{}
+	}
+
+	return shingles
+}
+
+func Jaccard(a, b map[string]struct{}) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+
+	intersection := 0
+	for s := range a {
+		if _, ok := b[s]; ok {
+			intersection++
+		}
+	}
+
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
+
+func Similarity(a, b string) float64 {
+	return Jaccard(Shingles(a, 1), Shingles(b, 1))
+}
+
+func isWord(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
@@ -2,6 +2,7 @@ package validation
 
 import (
 	"context"
+	"fmt"
 	"runtime"
 	"slices"
 	"strings"
@@ -35,6 +36,24 @@ func (t ItemType) String() string {
 	return "Unknown"
 }
 
+// MarshalText names the type in the keys of a Path in JSON.
+func (t ItemType) MarshalText() ([]byte, error) {
+	if t > Answer {
+		return nil, fmt.Errorf("unknown item type %d", t)
+	}
+	return []byte(strings.ToLower(t.String())), nil
+}
+
+func (t *ItemType) UnmarshalText(b []byte) error {
+	for itemType := Article; itemType <= Answer; itemType++ {
+		if strings.EqualFold(itemType.String(), string(b)) {
+			*t = itemType
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown item type %q", b)
+}
+
 type Path map[ItemType]int
 
 func (p Path) To(itemType ItemType) int {